@@ -0,0 +1,129 @@
+// caminho.go - busca de caminho em largura (BFS) sobre o mapa
+package main
+
+var (
+	direcoes         = []Position{{0, -1}, {-1, 0}, {0, 1}, {1, 0}}
+	direcoesDiagonal = []Position{{0, -1}, {-1, 0}, {0, 1}, {1, 0}, {-1, -1}, {1, -1}, {-1, 1}, {1, 1}}
+)
+
+// jogoCaminho devolve as células de de (exclusive) até para (inclusive) por
+// um menor caminho em que todas as células satisfazem passavel. Explora no
+// máximo limite células (0 = sem limite); nil se não houver caminho.
+func jogoCaminho(de, para Position, limite int, passavel func(x, y int) bool) []Position {
+	return buscarCaminho(de, para, limite, passavel, direcoes)
+}
+
+// jogoCaminhoDiagonal é jogoCaminho com passos diagonais, que só valem com as
+// duas células ortogonais livres (não corta quina de parede).
+func jogoCaminhoDiagonal(de, para Position, limite int, passavel func(x, y int) bool) []Position {
+	return buscarCaminho(de, para, limite, passavel, direcoesDiagonal)
+}
+
+func buscarCaminho(de, para Position, limite int, passavel func(x, y int) bool, dirs []Position) []Position {
+	if de == para {
+		return []Position{}
+	}
+	anterior := map[Position]Position{de: de}
+	fila := []Position{de}
+	for len(fila) > 0 {
+		atual := fila[0]
+		fila = fila[1:]
+		for _, d := range dirs {
+			p := Position{atual.X + d.X, atual.Y + d.Y}
+			if _, visto := anterior[p]; visto || !passavel(p.X, p.Y) {
+				continue
+			}
+			if d.X != 0 && d.Y != 0 && (!passavel(atual.X+d.X, atual.Y) || !passavel(atual.X, atual.Y+d.Y)) {
+				continue
+			}
+			anterior[p] = atual
+			if p == para {
+				var caminho []Position
+				for c := p; c != de; c = anterior[c] {
+					caminho = append(caminho, c)
+				}
+				for i, k := 0, len(caminho)-1; i < k; i, k = i+1, k-1 {
+					caminho[i], caminho[k] = caminho[k], caminho[i]
+				}
+				return caminho
+			}
+			if limite > 0 && len(anterior) > limite {
+				return nil
+			}
+			fila = append(fila, p)
+		}
+	}
+	return nil
+}
+
+// jogoSemParede informa se (x, y) está no mapa e não é parede (ignora
+// personagens e itens, que não bloqueiam o movimento dos outros jogadores).
+func jogoSemParede(jogo *Jogo, x, y int) bool {
+	if y < 0 || y >= len(jogo.Mapa) || x < 0 || x >= len(jogo.Mapa[y]) {
+		return false
+	}
+	return jogo.Mapa[y][x] != Parede
+}
+
+// vizinhas informa se a e b são células diferentes que se tocam (inclui diagonal).
+func vizinhas(a, b Position) bool {
+	return a != b && abs(a.X-b.X) <= 1 && abs(a.Y-b.Y) <= 1
+}
+
+// mapaLeitura é uma cópia do mapa entregue aos monstros: eles calculam
+// caminhos e visão sem tocar em Jogo.Mapa, que é do loop do jogo. Paredes e
+// vegetação só mudam com a troca de mapa, quando os monstros são reiniciados.
+type mapaLeitura [][]celulaLeitura
+
+type celulaLeitura struct {
+	livre     bool // não tangível
+	opaca     bool // bloqueia a visão (parede)
+	vegetacao bool // esconde e atrapalha a visão
+}
+
+func jogoMapaLeitura(jogo *Jogo) mapaLeitura {
+	m := make(mapaLeitura, len(jogo.Mapa))
+	for y, linha := range jogo.Mapa {
+		m[y] = make([]celulaLeitura, len(linha))
+		for x, e := range linha {
+			m[y][x] = celulaLeitura{livre: !e.tangivel, opaca: e == Parede, vegetacao: e == Vegetacao}
+		}
+	}
+	return m
+}
+
+func (m mapaLeitura) celula(x, y int) celulaLeitura {
+	if y < 0 || y >= len(m) || x < 0 || x >= len(m[y]) {
+		return celulaLeitura{opaca: true} // fora do mapa
+	}
+	return m[y][x]
+}
+
+// livre informa se (x, y) está no mapa e não é tangível.
+func (m mapaLeitura) livre(x, y int) bool { return m.celula(x, y).livre }
+
+func (m mapaLeitura) opaca(x, y int) bool { return m.celula(x, y).opaca }
+
+func (m mapaLeitura) vegetacao(x, y int) bool { return m.celula(x, y).vegetacao }
+
+// alcancaveis devolve as células livres ligadas a de (passos ortogonais).
+func (m mapaLeitura) alcancaveis(de Position) map[Position]bool {
+	area := make(map[Position]bool)
+	if !m.livre(de.X, de.Y) {
+		return area
+	}
+	area[de] = true
+	fila := []Position{de}
+	for len(fila) > 0 {
+		atual := fila[0]
+		fila = fila[1:]
+		for _, d := range direcoes {
+			p := Position{atual.X + d.X, atual.Y + d.Y}
+			if !area[p] && m.livre(p.X, p.Y) {
+				area[p] = true
+				fila = append(fila, p)
+			}
+		}
+	}
+	return area
+}
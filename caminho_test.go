@@ -0,0 +1,83 @@
+package main
+
+import "testing"
+
+// grade monta passavel a partir de linhas de texto ('#' = parede).
+func grade(linhas ...string) func(x, y int) bool {
+	return func(x, y int) bool {
+		if y < 0 || y >= len(linhas) || x < 0 || x >= len(linhas[y]) {
+			return false
+		}
+		return linhas[y][x] != '#'
+	}
+}
+
+func TestBuscarCaminho(t *testing.T) {
+	tests := []struct {
+		nome     string
+		mapa     []string
+		de, para Position
+		diagonal bool
+		limite   int
+		passos   int // -1 = sem caminho
+	}{
+		{"mesma célula", []string{"..."}, Position{1, 0}, Position{1, 0}, false, 0, 0},
+		{"reta", []string{"....."}, Position{0, 0}, Position{4, 0}, false, 0, 4},
+		{"contorna parede", []string{
+			"...",
+			".#.",
+			"...",
+		}, Position{0, 1}, Position{2, 1}, false, 0, 4},
+		{"diagonal livre", []string{
+			"...",
+			"...",
+			"...",
+		}, Position{0, 0}, Position{2, 2}, true, 0, 2},
+		{"diagonal não corta quina", []string{
+			".#",
+			"..",
+		}, Position{0, 0}, Position{1, 1}, true, 0, 2},
+		{"diagonal entre duas paredes", []string{
+			".#",
+			"#.",
+		}, Position{0, 0}, Position{1, 1}, true, 0, -1},
+		{"alvo fechado", []string{
+			"..#.",
+			"..#.",
+		}, Position{0, 0}, Position{3, 1}, false, 0, -1},
+		{"alvo é parede", []string{"..#"}, Position{0, 0}, Position{2, 0}, false, 0, -1},
+		{"limite de células", []string{"........"}, Position{0, 0}, Position{7, 0}, false, 3, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nome, func(t *testing.T) {
+			passavel := grade(tt.mapa...)
+			busca := jogoCaminho
+			if tt.diagonal {
+				busca = jogoCaminhoDiagonal
+			}
+			caminho := busca(tt.de, tt.para, tt.limite, passavel)
+			if tt.passos < 0 {
+				if caminho != nil {
+					t.Fatalf("expected no path, got %v", caminho)
+				}
+				return
+			}
+			if len(caminho) != tt.passos {
+				t.Fatalf("expected %d steps, got %v", tt.passos, caminho)
+			}
+			atual := tt.de
+			for _, p := range caminho {
+				if !vizinhas(atual, p) || !passavel(p.X, p.Y) {
+					t.Fatalf("invalid step %v -> %v in %v", atual, p, caminho)
+				}
+				if p.X != atual.X && p.Y != atual.Y && (!passavel(p.X, atual.Y) || !passavel(atual.X, p.Y)) {
+					t.Fatalf("step %v -> %v cuts a wall corner", atual, p)
+				}
+				atual = p
+			}
+			if atual != tt.para {
+				t.Fatalf("path ends at %v, want %v", atual, tt.para)
+			}
+		})
+	}
+}
@@ -1,31 +1,97 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	cl "jogo/common/client"
+	"jogo/common/shared"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
 	addr := flag.String("addr", "localhost:12345", "server address (ip:port)")
 	name := flag.String("name", "Player", "player name")
-	uiAddr := flag.String("ui", "127.0.0.1:4001", "local UI state broadcast address (ip:port)")
-	listenAddr := flag.String("listen", "127.0.0.1:4000", "local command listener address for MOVE messages from UI (ip:port)")
+	uiAddr := flag.String("ui", "127.0.0.1:4001", "local game session address: state and moves on one connection (ip:port or unix:/path)")
+	listenAddr := flag.String("listen", "", "optional one-shot command listener for legacy tools (ip:port or unix:/path); empty = disabled")
+	tlsCA := flag.String("tls-ca", "", "CA file used to verify the server certificate; empty = plaintext")
+	tlsPin := flag.String("tls-pin", "", "expected SHA-256 fingerprint of the server certificate (hex)")
+	tlsCert := flag.String("tls-cert", "", "client certificate file (PEM), if the server requires one")
+	tlsKey := flag.String("tls-key", "", "client private key file (PEM)")
+	tlsName := flag.String("tls-server-name", "", "server name expected in the certificate (default: host of --addr)")
+	bot := flag.String("bot", "", "run headless with a policy instead of serving a local game: random, path or items")
+	botPath := flag.String("bot-path", "", "step file for --bot path (W/A/S/D letters, # comments)")
+	botRate := flag.Float64("bot-rate", 5, "bot moves per second")
+	botMoves := flag.Int("bot-moves", 0, "stop the bot after this many moves (0 = until the policy ends or Ctrl+C)")
 	flag.Parse()
 
-	client, err := cl.NewClient(*name, *addr)
+	var policy cl.BotPolicy
+	switch *bot {
+	case "":
+	case "random":
+		policy = cl.NewRandomWalk(time.Now().UnixNano())
+	case "path":
+		p, err := cl.LoadScriptedPath(*botPath)
+		if err != nil {
+			log.Fatalf("Invalid --bot-path: %v", err)
+		}
+		policy = p
+	case "items":
+		policy = cl.NearestItem{}
+	default:
+		log.Fatalf("Invalid --bot %q: use random, path or items", *bot)
+	}
+
+	tlsCfg, err := shared.ClientTLSConfig(shared.TLSOptions{
+		CertFile:   *tlsCert,
+		KeyFile:    *tlsKey,
+		CAFile:     *tlsCA,
+		PinSHA256:  *tlsPin,
+		ServerName: *tlsName,
+	})
+	if err != nil {
+		log.Fatalf("Invalid TLS configuration: %v", err)
+	}
+
+	client, err := cl.NewClient(*name, *addr, tlsCfg)
 	if err != nil {
 		log.Fatalf("Failed to connect/register: %v", err)
 	}
 
+	// Ctrl+C: encerra o bot ou fecha os listeners para não deixar sockets unix para trás
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+
+	if policy != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-sig
+			cancel()
+		}()
+		st, err := client.RunBot(ctx, policy, *botRate, *botMoves)
+		if err != nil {
+			log.Fatalf("Bot failed: %v", err)
+		}
+		fmt.Printf("[BOT %s] done: %s\n", *name, st)
+		return
+	}
+
 	client.StartPolling()
-	// broadcast state to local UI
+	client.StartPinger(2 * time.Second)
+	// sessão com o jogo local (estado e movimentos)
 	if err := client.StartLocalStateBroadcaster(*uiAddr); err != nil {
-		log.Fatalf("Failed to start local state broadcaster: %v", err)
+		log.Fatalf("Failed to start local game sessions: %v", err)
 	}
-	if err := client.StartLocalCommandListener(*listenAddr); err != nil {
-		log.Fatalf("Failed to start local command listener: %v", err)
+	if *listenAddr != "" {
+		if err := client.StartLocalCommandListener(*listenAddr); err != nil {
+			log.Fatalf("Failed to start local command listener: %v", err)
+		}
 	}
 
-	select {}
+	<-sig
+	client.CloseLocalListeners()
 }
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	cl "jogo/common/client"
+	"jogo/common/shared"
+)
+
+// opStats acumula contagem, erros e latências de um tipo de chamada.
+type opStats struct {
+	mu        sync.Mutex
+	name      string
+	latencies []time.Duration
+	errors    map[string]int
+	ticks     atomic.Int64 // chamadas concluídas desde o último relatório
+}
+
+func newOpStats(name string) *opStats {
+	return &opStats{name: name, errors: make(map[string]int)}
+}
+
+// record registra uma chamada; reason != "" conta como erro.
+func (s *opStats) record(d time.Duration, reason string) {
+	s.ticks.Add(1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if reason != "" {
+		s.errors[reason]++
+		return
+	}
+	s.latencies = append(s.latencies, d)
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted)-1) * p)
+	return sorted[i]
+}
+
+func (s *opStats) report(elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lat := append([]time.Duration(nil), s.latencies...)
+	sort.Slice(lat, func(i, k int) bool { return lat[i] < lat[k] })
+	nerr := 0
+	for _, n := range s.errors {
+		nerr += n
+	}
+	total := len(lat) + nerr
+	fmt.Printf("%-8s ok=%-8d errors=%-6d %8.1f ops/s   p50=%-9s p90=%-9s p99=%-9s max=%s\n",
+		s.name, len(lat), nerr, float64(total)/elapsed.Seconds(),
+		percentile(lat, 0.50).Round(time.Microsecond), percentile(lat, 0.90).Round(time.Microsecond),
+		percentile(lat, 0.99).Round(time.Microsecond), percentile(lat, 1).Round(time.Microsecond))
+	reasons := make([]string, 0, len(s.errors))
+	for r := range s.errors {
+		reasons = append(reasons, r)
+	}
+	sort.Strings(reasons)
+	for _, r := range reasons {
+		fmt.Printf("         %6d x %s\n", s.errors[r], r)
+	}
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:12345", "server address (ip:port)")
+	clients := flag.Int("clients", 50, "number of simulated players")
+	ramp := flag.Duration("ramp", 5*time.Second, "time to bring all players online (evenly spaced)")
+	duration := flag.Duration("duration", 30*time.Second, "test length, counted after the ramp-up")
+	moveRate := flag.Float64("move-rate", 5, "MOVE commands per second per player (0 = none)")
+	pollRate := flag.Float64("poll-rate", 2, "GetState calls per second per player (0 = none)")
+	tlsPin := flag.String("tls-pin", "", "expected SHA-256 fingerprint of the server certificate (hex); empty = plaintext")
+	flag.Parse()
+
+	if *clients <= 0 || *moveRate < 0 || *pollRate < 0 {
+		log.Fatalf("--clients must be positive and rates non-negative")
+	}
+	tlsCfg, err := shared.ClientTLSConfig(shared.TLSOptions{PinSHA256: *tlsPin})
+	if err != nil {
+		log.Fatalf("Invalid TLS configuration: %v", err)
+	}
+	// os logs por comando do client dominariam a saída
+	cl.SetLogOutput(io.Discard)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		fmt.Println("interrupted, reporting partial results")
+		cancel()
+	}()
+
+	reg, move, state := newOpStats("register"), newOpStats("move"), newOpStats("state")
+	var online atomic.Int64
+	var wg sync.WaitGroup
+
+	fmt.Printf("loadgen: %d players against %s, ramp %s, run %s, %.1f moves/s and %.1f polls/s each\n",
+		*clients, *addr, *ramp, *duration, *moveRate, *pollRate)
+	begin := time.Now()
+	// o ramp-up também conta no wg: os Add abaixo acontecem antes do Wait acabar
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var step time.Duration
+		if *clients > 1 {
+			step = *ramp / time.Duration(*clients-1)
+		}
+		for i := 0; i < *clients; i++ {
+			if i > 0 && step > 0 {
+				select {
+				case <-time.After(step):
+				case <-ctx.Done():
+					return
+				}
+			}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				t0 := time.Now()
+				c, err := cl.NewClient(fmt.Sprintf("load%04d", i), *addr, tlsCfg)
+				if err != nil {
+					reg.record(0, err.Error())
+					return
+				}
+				reg.record(time.Since(t0), "")
+				online.Add(1)
+				runPlayer(ctx, c, int64(i), *moveRate, *pollRate, move, state)
+				_ = c.Unregister() // libera a vaga na sala (max_players)
+			}(i)
+		}
+	}()
+
+	// relatório a cada segundo até o fim do ramp-up + duração
+	end := time.NewTimer(*ramp + *duration)
+	report := time.NewTicker(time.Second)
+	defer report.Stop()
+running:
+	for {
+		select {
+		case <-report.C:
+			fmt.Printf("[%5.1fs] online=%d  move=%d/s  state=%d/s\n", time.Since(begin).Seconds(),
+				online.Load(), move.ticks.Swap(0), state.ticks.Swap(0))
+		case <-end.C:
+			break running
+		case <-ctx.Done():
+			break running
+		}
+	}
+	cancel()
+	wg.Wait()
+	elapsed := time.Since(begin)
+
+	fmt.Printf("\nsummary after %s (%d/%d players registered)\n", elapsed.Round(time.Millisecond), online.Load(), *clients)
+	reg.report(elapsed)
+	move.report(elapsed)
+	state.report(elapsed)
+}
+
+// runPlayer anda ao acaso pelo mapa e consulta o estado nas taxas pedidas.
+func runPlayer(ctx context.Context, c *cl.Client, seed int64, moveRate, pollRate float64, move, state *opStats) {
+	var m *cl.BotMap
+	x, y := 0, 0
+	if gs, err := c.State(); err == nil && len(gs.MapLines) > 0 {
+		m = cl.NewBotMap(gs.MapLines)
+		for _, p := range gs.Players {
+			if p.ID == c.ID() {
+				x, y = p.X, p.Y
+			}
+		}
+		// spawn numa parede (política "origin"): o primeiro passo sai dela
+		if nx, ny, ok := m.NearestWalkable(x, y); ok {
+			x, y = nx, ny
+		}
+	}
+	walk := cl.NewRandomWalk(seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	moveC, stopMove := ticker(moveRate, rng)
+	defer stopMove()
+	pollC, stopPoll := ticker(pollRate, rng)
+	defer stopPoll()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-moveC:
+			// sem mapa (servidor v1): anda de um lado para o outro
+			dx, dy, ok := 1-rng.Intn(3), 0, true
+			if m != nil {
+				dx, dy, ok = walk.Next(m, x, y)
+			}
+			if !ok {
+				continue
+			}
+			x, y = x+dx, y+dy
+			t0 := time.Now()
+			rep, err := c.SendMove(x, y)
+			switch {
+			case err != nil:
+				move.record(0, err.Error())
+			case !rep.Applied:
+				move.record(0, rep.Error)
+			default:
+				move.record(time.Since(t0), "")
+			}
+		case <-pollC:
+			t0 := time.Now()
+			if _, err := c.State(); err != nil {
+				state.record(0, err.Error())
+			} else {
+				state.record(time.Since(t0), "")
+			}
+		}
+	}
+}
+
+// ticker dispara rate vezes por segundo, com fase aleatória para os jogadores
+// não baterem no servidor ao mesmo tempo. rate 0 nunca dispara.
+func ticker(rate float64, rng *rand.Rand) (<-chan time.Time, func()) {
+	if rate <= 0 {
+		return nil, func() {}
+	}
+	period := time.Duration(float64(time.Second) / rate)
+	time.Sleep(time.Duration(rng.Int63n(int64(period) + 1)))
+	t := time.NewTicker(period)
+	return t.C, t.Stop
+}
@@ -3,18 +3,120 @@ package main
 import (
 	"flag"
 	sv "jogo/common/server"
+	"jogo/common/shared"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
-	addr := flag.String("addr", "0.0.0.0:12345", "server listen address")
+	addr := flag.String("addr", "0.0.0.0:12345", "server listen address (overrides \"listen\" from --config)")
+	configPath := flag.String("config", "", "JSON config file (maps, tick rate, limits, spawn policy, rounds); reloaded on SIGHUP")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file (PEM); empty = plaintext")
+	tlsKey := flag.String("tls-key", "", "TLS private key file (PEM)")
+	tlsCA := flag.String("tls-ca", "", "CA file used to require and verify client certificates")
+	tlsSelf := flag.Bool("tls-self-signed", false, "generate a self-signed certificate in memory (LAN play)")
+	jsonrpcAddr := flag.String("jsonrpc-addr", "", "optional JSON-RPC listen address for non-Go clients (e.g. 0.0.0.0:12346)")
+	httpAddr := flag.String("http-addr", "", "optional HTTP/JSON gateway listen address (e.g. 0.0.0.0:8080)")
+	spectatorAddr := flag.String("spectator-addr", "", "optional spectator page + SSE feed listen address (e.g. 0.0.0.0:8081)")
+	adminAddr := flag.String("admin-addr", "", "optional admin HTTP listen address (requires --admin-token)")
+	adminToken := flag.String("admin-token", "", "bearer token for the admin interface")
+	ac := sv.DefaultAntiCheatConfig()
+	flag.Float64Var(&ac.MaxMoveRate, "max-move-rate", ac.MaxMoveRate, "max sustained MOVEs per second per client (0 = off)")
+	flag.IntVar(&ac.Burst, "move-burst", ac.Burst, "MOVE burst tolerated above --max-move-rate")
+	flag.IntVar(&ac.MaxStep, "max-step", ac.MaxStep, "max cells per MOVE (0 = off)")
+	flag.StringVar(&ac.Action, "anticheat-action", ac.Action, "action on violations: flag, throttle or kick")
+	flag.IntVar(&ac.KickAfter, "kick-after", ac.KickAfter, "violations before a kick (with --anticheat-action=kick); the count resets after a minute without violations")
 	flag.Parse()
 
-	_, err := sv.StartRPCServer(*addr)
+	tlsCfg, err := shared.ServerTLSConfig(shared.TLSOptions{
+		CertFile:   *tlsCert,
+		KeyFile:    *tlsKey,
+		CAFile:     *tlsCA,
+		SelfSigned: *tlsSelf,
+	})
+	if err != nil {
+		log.Fatalf("invalid TLS configuration: %v", err)
+	}
+
+	// flags passadas explicitamente têm precedência sobre o arquivo
+	setFlags := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+	override := func(cfg *sv.Config) {
+		if setFlags["addr"] {
+			cfg.Listen = *addr
+		}
+		a := &cfg.Limits.AntiCheat
+		if setFlags["max-move-rate"] {
+			a.MaxMoveRate = ac.MaxMoveRate
+		}
+		if setFlags["move-burst"] {
+			a.Burst = ac.Burst
+		}
+		if setFlags["max-step"] {
+			a.MaxStep = ac.MaxStep
+		}
+		if setFlags["anticheat-action"] {
+			a.Action = ac.Action
+		}
+		if setFlags["kick-after"] {
+			a.KickAfter = ac.KickAfter
+		}
+	}
+
+	var gs *sv.GameServer
+	listenAddr := *addr
+	if *configPath != "" {
+		gs, err = sv.NewGameServerFromFile(*configPath, override)
+		if err != nil {
+			log.Fatalf("invalid config: %v", err)
+		}
+		listenAddr = gs.Config().Listen
+		go reloadOnSIGHUP(gs)
+	} else {
+		gs = sv.NewGameServer()
+		if err := gs.SetAntiCheatConfig(ac); err != nil {
+			log.Fatalf("invalid anti-cheat configuration: %v", err)
+		}
+	}
+	_, err = sv.StartRPCServer(gs, listenAddr, tlsCfg)
 	if err != nil {
 		log.Fatalf("failed to start RPC server: %v", err)
 	}
+	if *jsonrpcAddr != "" {
+		if _, err := sv.StartJSONRPCServer(gs, *jsonrpcAddr, tlsCfg); err != nil {
+			log.Fatalf("failed to start JSON-RPC server: %v", err)
+		}
+	}
+	if *httpAddr != "" {
+		if _, err := sv.StartHTTPGateway(gs, *httpAddr, tlsCfg); err != nil {
+			log.Fatalf("failed to start HTTP gateway: %v", err)
+		}
+	}
+	if *spectatorAddr != "" {
+		if _, err := sv.StartSpectatorServer(gs, *spectatorAddr, tlsCfg); err != nil {
+			log.Fatalf("failed to start spectator feed: %v", err)
+		}
+	}
+
+	if *adminAddr != "" {
+		if _, err := sv.StartAdminServer(gs, *adminAddr, *adminToken, tlsCfg); err != nil {
+			log.Fatalf("failed to start admin interface: %v", err)
+		}
+	}
 
 	// block forever
 	select {}
 }
+
+// reloadOnSIGHUP relê o arquivo de configuração a cada SIGHUP.
+func reloadOnSIGHUP(gs *sv.GameServer) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	for range ch {
+		if _, err := gs.Reload(); err != nil {
+			log.Printf("config reload failed, keeping current rules: %v", err)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+// bot.go - jogador automático (sem terminal) para demonstrações e testes
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+)
+
+// Parede: única célula intransponível para o bot (mesmo símbolo do jogo)
+const botWall = '▤'
+
+// botItems são os itens coletáveis: invisibilidade e estrela.
+var botItems = map[rune]bool{'¤': true, '★': true}
+
+// BotMap é a visão do bot sobre GameState.MapLines.
+type BotMap struct {
+	cells [][]rune
+	items map[[2]int]bool
+}
+
+// NewBotMap monta o mapa a partir das linhas enviadas pelo servidor.
+func NewBotMap(lines []string) *BotMap {
+	m := &BotMap{items: make(map[[2]int]bool)}
+	for y, line := range lines {
+		row := []rune(line)
+		for x, ch := range row {
+			if botItems[ch] {
+				m.items[[2]int{x, y}] = true
+			}
+		}
+		m.cells = append(m.cells, row)
+	}
+	return m
+}
+
+// Walkable informa se (x, y) está dentro do mapa e não é parede.
+func (m *BotMap) Walkable(x, y int) bool {
+	if y < 0 || y >= len(m.cells) || x < 0 || x >= len(m.cells[y]) {
+		return false
+	}
+	return m.cells[y][x] != botWall
+}
+
+// Items devolve quantos itens ainda não foram coletados.
+func (m *BotMap) Items() int { return len(m.items) }
+
+// collect remove o item em (x, y), se houver.
+func (m *BotMap) collect(x, y int) bool {
+	p := [2]int{x, y}
+	if !m.items[p] {
+		return false
+	}
+	delete(m.items, p)
+	return true
+}
+
+// NearestWalkable acha a célula livre mais próxima de (x, y) (ponto de
+// partida do bot quando o servidor o coloca numa parede, ex.: spawn "origin").
+func (m *BotMap) NearestWalkable(x, y int) (int, int, bool) {
+	best, bx, by := -1, 0, 0
+	for cy, row := range m.cells {
+		for cx, ch := range row {
+			if ch == botWall {
+				continue
+			}
+			d := abs(cx-x) + abs(cy-y)
+			if best < 0 || d < best {
+				best, bx, by = d, cx, cy
+			}
+		}
+	}
+	return bx, by, best >= 0
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+var botDirs = [4][2]int{{0, -1}, {-1, 0}, {0, 1}, {1, 0}} // W A S D
+
+// BotPolicy escolhe o próximo passo. ok=false encerra o bot.
+type BotPolicy interface {
+	Next(m *BotMap, x, y int) (dx, dy int, ok bool)
+}
+
+// RandomWalk anda ao acaso, mantendo a direção na maior parte das vezes.
+type RandomWalk struct {
+	rng *rand.Rand
+	dir int
+}
+
+func NewRandomWalk(seed int64) *RandomWalk {
+	return &RandomWalk{rng: rand.New(rand.NewSource(seed))}
+}
+
+func (p *RandomWalk) Next(m *BotMap, x, y int) (int, int, bool) {
+	d := botDirs[p.dir]
+	if p.rng.Intn(10) < 7 && m.Walkable(x+d[0], y+d[1]) {
+		return d[0], d[1], true
+	}
+	for _, i := range p.rng.Perm(len(botDirs)) {
+		d := botDirs[i]
+		if m.Walkable(x+d[0], y+d[1]) {
+			p.dir = i
+			return d[0], d[1], true
+		}
+	}
+	return 0, 0, false // cercado por paredes
+}
+
+// ScriptedPath segue uma sequência de passos W/A/S/D; termina no fim dela.
+type ScriptedPath struct {
+	steps []int
+	pos   int
+}
+
+// LoadScriptedPath lê um roteiro: letras W, A, S, D (um passo cada, como no
+// jogo); espaços são ignorados e linhas começando com '#' são comentários.
+func LoadScriptedPath(path string) (*ScriptedPath, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p := &ScriptedPath{}
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, ch := range strings.ToUpper(line) {
+			i := strings.IndexRune("WASD", ch)
+			switch {
+			case i >= 0:
+				p.steps = append(p.steps, i)
+			case ch == ' ' || ch == '\t':
+			default:
+				return nil, fmt.Errorf("%s:%d: invalid step %q (use W, A, S, D)", path, n, ch)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(p.steps) == 0 {
+		return nil, fmt.Errorf("%s: no steps", path)
+	}
+	return p, nil
+}
+
+func (p *ScriptedPath) Next(m *BotMap, x, y int) (int, int, bool) {
+	if p.pos >= len(p.steps) {
+		return 0, 0, false
+	}
+	d := botDirs[p.steps[p.pos]]
+	p.pos++
+	return d[0], d[1], true
+}
+
+// NearestItem vai até o item mais próximo (busca em largura); termina quando
+// não sobra item alcançável.
+type NearestItem struct{}
+
+func (NearestItem) Next(m *BotMap, x, y int) (int, int, bool) {
+	start := [2]int{x, y}
+	first := map[[2]int][2]int{start: {0, 0}} // primeiro passo que leva a cada célula
+	queue := [][2]int{start}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur != start && m.items[cur] {
+			d := first[cur]
+			return d[0], d[1], true
+		}
+		for _, d := range botDirs {
+			nx, ny := cur[0]+d[0], cur[1]+d[1]
+			n := [2]int{nx, ny}
+			if _, seen := first[n]; seen || !m.Walkable(nx, ny) {
+				continue
+			}
+			if cur == start {
+				first[n] = d
+			} else {
+				first[n] = first[cur]
+			}
+			queue = append(queue, n)
+		}
+	}
+	return 0, 0, false
+}
+
+// BotStats resume uma execução do bot.
+type BotStats struct {
+	Moves     int    // movimentos decididos pela política
+	Sent      uint64 // aplicados pelo servidor
+	Rejected  uint64 // recusados pelo servidor (ex.: rate limited)
+	Failed    uint64 // perdidos por erro de RPC
+	Collected int    // itens alcançados
+	Duration  time.Duration
+}
+
+func (s BotStats) String() string {
+	return fmt.Sprintf("moves=%d sent=%d rejected=%d failed=%d collected=%d in %s",
+		s.Moves, s.Sent, s.Rejected, s.Failed, s.Collected, s.Duration.Round(time.Millisecond))
+}
+
+// RunBot joga com a política dada, rate movimentos por segundo, até a política
+// terminar, maxMoves (0 = sem limite) ou ctx ser cancelado.
+func (c *Client) RunBot(ctx context.Context, policy BotPolicy, rate float64, maxMoves int) (BotStats, error) {
+	var st BotStats
+	if rate <= 0 {
+		return st, errors.New("bot rate must be positive")
+	}
+	begin := time.Now()
+	before := c.OutboxStats()
+
+	m, x, y, mapKey, err := c.botState()
+	if err != nil {
+		return st, err
+	}
+	if !m.Walkable(x, y) {
+		nx, ny, ok := m.NearestWalkable(x, y)
+		if !ok {
+			return st, errors.New("map has no walkable cell")
+		}
+		x, y = nx, ny
+		c.Enqueue("MOVE", x, y, 0)
+		st.Moves++
+	}
+
+	tick := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer tick.Stop()
+	lastRefresh := time.Now()
+loop:
+	for maxMoves == 0 || st.Moves < maxMoves {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-tick.C:
+		}
+		// o servidor pode trocar de mapa entre rodadas
+		if time.Since(lastRefresh) > time.Second {
+			lastRefresh = time.Now()
+			if nm, nx, ny, key, err := c.botState(); err == nil && key != mapKey {
+				m, x, y, mapKey = nm, nx, ny, key
+			}
+		}
+		dx, dy, ok := policy.Next(m, x, y)
+		if !ok {
+			break
+		}
+		if !m.Walkable(x+dx, y+dy) {
+			continue // passo do roteiro contra a parede: fica parado, como no jogo
+		}
+		x, y = x+dx, y+dy
+		c.Enqueue("MOVE", x, y, 0)
+		st.Moves++
+		if m.collect(x, y) {
+			st.Collected++
+		}
+	}
+
+	// espera a fila de saída terminar para o resumo refletir as respostas
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		cur := c.OutboxStats()
+		done := (cur.Sent - before.Sent) + (cur.Rejected - before.Rejected) + (cur.Failed - before.Failed) +
+			(cur.Coalesced - before.Coalesced) + (cur.Dropped - before.Dropped)
+		if done >= uint64(st.Moves) {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	after := c.OutboxStats()
+	st.Sent = after.Sent - before.Sent
+	st.Rejected = after.Rejected - before.Rejected
+	st.Failed = after.Failed - before.Failed
+	st.Duration = time.Since(begin)
+	return st, nil
+}
+
+// botState busca o mapa e a posição do bot no servidor.
+func (c *Client) botState() (m *BotMap, x, y int, mapKey string, err error) {
+	gs, err := c.State()
+	if err != nil {
+		return nil, 0, 0, "", err
+	}
+	if len(gs.MapLines) == 0 {
+		return nil, 0, 0, "", errors.New("server sent no map (needs map_lines support)")
+	}
+	self := c.ID()
+	for _, p := range gs.Players {
+		if p.ID == self {
+			x, y = p.X, p.Y
+		}
+	}
+	return NewBotMap(gs.MapLines), x, y, strings.Join(gs.MapLines, "\n"), nil
+}
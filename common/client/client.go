@@ -2,86 +2,151 @@
 package client
 
 import (
-	"bufio"
+	"crypto/tls"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net"
 	"net/rpc"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"jogo/common/protocol"
 	"jogo/common/shared"
 )
 
 // ---- Cliente ----
 type Client struct {
+	name   string
+	addr   string      // endereço do servidor (para reconectar)
+	tlsCfg *tls.Config // nil = texto puro
+
+	// conexão atual; trocada pelo reconnectLoop
+	connMu    sync.Mutex
 	rpcClient *rpc.Client
-	name      string
 	clientID  string
+	features  []string // funcionalidades negociadas com o servidor
+	online    bool
+
+	outbox *outbox // fila de saída (MOVEs coalescidos), ver outbox.go
 
 	mu sync.Mutex
 
 	x, y int
 	seq  uint64
 
-	// local state broadcaster
+	// último comando confirmado: sequência do servidor e do jogo (SELFPOS)
+	lastAckSeq uint64
+	lastAckTag uint64
+
+	clock clockSync // RTT e offset em relação ao servidor
+
+	events *eventHub // callbacks/canais de eventos (ver events.go)
+
+	// sessões locais com os jogos (ver session.go)
 	subsMu  sync.Mutex
-	subs    map[net.Conn]struct{}
+	subs    map[*localSession]struct{}
 	stateLn net.Listener
+	cmdLn   net.Listener
 }
 
-func NewClient(name string, rpcAddr string) (*Client, error) {
-	c := &Client{name: name, x: 0, y: 0, seq: 0, subs: make(map[net.Conn]struct{})}
-	conn, err := rpc.Dial("tcp", rpcAddr)
+// NewClient conecta ao servidor e registra o jogador. tlsCfg nil = texto puro.
+// Depois disso, quedas de conexão são tratadas automaticamente (ver conn.go).
+func NewClient(name string, rpcAddr string, tlsCfg *tls.Config) (*Client, error) {
+	c := &Client{name: name, addr: rpcAddr, tlsCfg: tlsCfg, x: 0, y: 0, seq: 0,
+		outbox: newOutbox(), events: newEventHub(), subs: make(map[*localSession]struct{})}
+	conn, err := dialRPC(rpcAddr, tlsCfg)
 	if err != nil {
 		return nil, err
 	}
-	c.rpcClient = conn
-
-	var rr shared.RegisterReply
-	err = c.rpcClient.Call("GameServer.Register", shared.RegisterArgs{Name: name}, &rr)
+	rr, err := c.register(conn, "")
 	if err != nil {
+		conn.Close()
 		return nil, err
 	}
+	c.rpcClient = conn
 	c.clientID = rr.ClientID
-	fmt.Printf("[CLIENT %s] Registered with id=%s\n", name, c.clientID)
+	c.features = shared.NegotiateFeatures(rr.Version, rr.Features)
+	c.online = true
+	logf("[CLIENT %s] Registered with id=%s server=v%d features=%v\n",
+		name, c.clientID, shared.EffectiveVersion(rr.Version), c.features)
+	go c.runOutbox()
 	return c, nil
 }
 
-// sendCommandWithRetry (com backoff simples)
+// sendCommandWithRetry (com backoff simples). Só falhas de transporte são
+// repetidas: um rpc.ServerError (cliente desconhecido, kick...) voltaria igual.
 func (c *Client) sendCommandWithRetry(cmd shared.Command) (shared.CommandReply, error) {
 	var lastErr error
 	var rep shared.CommandReply
 
 	for attempt := 1; attempt <= 6; attempt++ {
-		fmt.Printf("[CLIENT %s] Sending command seq=%d attempt=%d pos=(%d,%d)\n",
+		logf("[CLIENT %s] Sending command seq=%d attempt=%d pos=(%d,%d)\n",
 			c.name, cmd.Sequence, attempt, cmd.ReportedX, cmd.ReportedY)
 
-		callErr := c.rpcClient.Call("GameServer.SendCommand", cmd, &rep)
+		callErr := c.call("GameServer.SendCommand", cmd, &rep)
 		if callErr == nil {
 			return rep, nil
 		}
+		var se rpc.ServerError
+		if errors.Is(callErr, ErrOffline) || errors.As(callErr, &se) {
+			return rep, callErr
+		}
 		lastErr = callErr
-		fmt.Printf("[CLIENT %s] RPC error: %v. Retrying...\n", c.name, callErr)
+		logf("[CLIENT %s] RPC error: %v. Retrying...\n", c.name, callErr)
 		time.Sleep(time.Duration(attempt*100) * time.Millisecond)
 	}
 	return rep, lastErr
 }
 
+// SendMove envia um MOVE e espera a resposta, sem passar pela fila de saída
+// (o cmd/loadgen mede a latência de cada chamada). Não misturar com Enqueue no
+// mesmo Client: as sequências poderiam chegar fora de ordem.
+func (c *Client) SendMove(x, y int) (shared.CommandReply, error) {
+	id := c.ID()
+	c.mu.Lock()
+	c.seq++
+	cmd := shared.Command{ClientID: id, Sequence: c.seq, ReportedX: x, ReportedY: y, CommandString: "MOVE"}
+	c.x, c.y = x, y
+	c.mu.Unlock()
+	var rep shared.CommandReply
+	err := c.call("GameServer.SendCommand", cmd, &rep)
+	return rep, err
+}
+
+// State busca o estado atual da sala no servidor.
+func (c *Client) State() (shared.GameState, error) {
+	var gs shared.GameState
+	if err := c.call("GameServer.GetState", shared.GetStateArgs{ClientID: c.ID()}, &gs); err != nil {
+		return gs, err
+	}
+	c.events.observe(gs)
+	return gs, nil
+}
+
+// Unregister tira o jogador da sala, liberando a vaga antes do idle_timeout
+// do servidor. O Client não deve mais ser usado depois.
+func (c *Client) Unregister() error {
+	return c.call("GameServer.Unregister", shared.UnregisterArgs{ClientID: c.ID()}, &shared.UnregisterReply{})
+}
+
 // polling do estado do servidor
 func (c *Client) StartPolling() {
 	go func() {
 		for {
-			var gs shared.GameState
-			err := c.rpcClient.Call("GameServer.GetState", shared.GetStateArgs{ClientID: c.clientID}, &gs)
-			if err != nil {
-				fmt.Printf("[CLIENT %s] GetState error: %v\n", c.name, err)
+			gs, err := c.State()
+			if errors.Is(err, ErrOffline) {
+				// mantém o aviso visível para jogos que conectarem durante a queda
+				c.broadcastStatus("reconnecting…")
+			} else if err != nil {
+				logf("[CLIENT %s] GetState error: %v\n", c.name, err)
 			} else {
-				fmt.Printf("\n[CLIENT %s] GetState: %d players at %s\n", c.name, len(gs.Players), gs.Time.Format("15:04:05"))
+				st := c.OutboxStats()
+				logf("\n[CLIENT %s] GetState: %d players at %s (outbox depth=%d sent=%d coalesced=%d rejected=%d)\n",
+					c.name, len(gs.Players), gs.Time.Format("15:04:05"), st.Depth, st.Sent, st.Coalesced, st.Rejected)
 				for _, p := range gs.Players {
-					fmt.Printf("   -> %s (%s): (%d,%d)\n", p.ID, p.Name, p.X, p.Y)
+					logf("   -> %s (%s): (%d,%d)\n", p.ID, p.Name, p.X, p.Y)
 				}
 				// broadcast to local UI listeners
 				c.broadcastState(gs)
@@ -91,18 +156,23 @@ func (c *Client) StartPolling() {
 	}()
 }
 
-// listener local para receber comandos do jogo (jogo.exe/jogo.go)
+// listener local opcional para comandos avulsos (uma conexão por comando, como
+// nas versões antigas do jogo); o jogo atual usa a sessão do StartLocalStateBroadcaster
 func (c *Client) StartLocalCommandListener(addr string) error {
-	ln, err := net.Listen("tcp", addr)
+	ln, err := protocol.Listen(addr)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("[CLIENT %s] Local command listener running on %s\n", c.name, addr)
+	c.cmdLn = ln
+	logf("[CLIENT %s] Local command listener running on %s\n", c.name, addr)
 	go func() {
 		for {
 			conn, err := ln.Accept()
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
 			if err != nil {
-				fmt.Printf("[CLIENT] accept error: %v\n", err)
+				logf("[CLIENT] accept error: %v\n", err)
 				continue
 			}
 			go c.handleLocalConn(conn)
@@ -111,176 +181,80 @@ func (c *Client) StartLocalCommandListener(addr string) error {
 	return nil
 }
 
+// CloseLocalListeners fecha os listeners locais (e remove os sockets unix).
+func (c *Client) CloseLocalListeners() {
+	for _, ln := range []net.Listener{c.stateLn, c.cmdLn} {
+		if ln != nil {
+			ln.Close()
+		}
+	}
+}
+
 func (c *Client) handleLocalConn(conn net.Conn) {
 	defer conn.Close()
-	scanner := bufio.NewScanner(conn)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-		// Espera formato: MOVE <x> <y>
-		parts := strings.Fields(line)
-		if len(parts) == 0 {
-			continue
-		}
-		switch strings.ToUpper(parts[0]) {
-		case "MOVE":
-			if len(parts) < 3 {
-				// protocolo local sem resposta
-				continue
-			}
-			x, err1 := strconv.Atoi(parts[1])
-			y, err2 := strconv.Atoi(parts[2])
-			if err1 != nil || err2 != nil {
-				continue
+	enc := protocol.NewEncoder(conn)
+	dec := protocol.NewDecoder(conn)
+	for {
+		msg, err := dec.Decode()
+		if err != nil {
+			switch {
+			case errors.Is(err, shared.ErrVersionMismatch):
+				// peer v2: responde no formato de texto que ele entende
+				logf("[CLIENT %s] local peer rejected: %v\n", c.name, err)
+				fmt.Fprintf(conn, "ERROR %v\n", err)
+			case err != io.EOF && !isClosedConnError(err):
+				logf("[CLIENT] local conn error: %v\n", err)
+				_ = enc.Encode(&protocol.Error{Message: err.Error()})
 			}
-			// envia comando ao servidor
-			c.mu.Lock()
-			c.x = x
-			c.y = y
-			c.seq++
-			seq := c.seq
-			c.mu.Unlock()
-
-			cmd := shared.Command{
-				ClientID:      c.clientID,
-				Sequence:      seq,
-				ReportedX:     x,
-				ReportedY:     y,
-				CommandString: "MOVE",
+			return
+		}
+		switch m := msg.(type) {
+		case *protocol.Hello:
+			if !c.answerHello(enc, m) {
+				return
 			}
+		case *protocol.Move:
 			// Handshake: responde imediatamente para permitir o jogo ler e fechar sem reset
-			if _, err := conn.Write([]byte("OK\n")); err != nil {
-				// ignorar erro de escrita se o outro lado fechar antes
-			}
-			_, _ = c.sendCommandWithRetry(cmd)
+			_ = enc.Encode(&protocol.OK{})
+			// envia comando ao servidor pela fila (não bloqueia o jogo)
+			_ = c.Enqueue("MOVE", m.X, m.Y, m.Seq)
 		default:
-			// comando desconhecido: ignorar
+			// mensagem desconhecida: ignorar
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		// Em Windows, quando o lado remoto fecha logo após enviar, pode vir wsarecv/aborted.
-		es := err.Error()
-		if strings.Contains(strings.ToLower(es), "wsarecv") ||
-			strings.Contains(strings.ToLower(es), "aborted") ||
-			strings.Contains(strings.ToLower(es), "reset") ||
-			strings.Contains(strings.ToLower(es), "closed") {
-			// tratar como fechamento normal
-			return
-		}
-		fmt.Printf("[CLIENT] local conn error: %v\n", err)
-	}
 }
 
-// ID returns this client's server-assigned id
-func (c *Client) ID() string { return c.clientID }
-
-// --- Integration helper: report positions from a shared channel ---
-func StartPositionReporter(posCh <-chan [2]int, clientID string, serverAddr string, wg *sync.WaitGroup) {
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		for {
-			select {
-			case pos := <-posCh:
-				conn, err := rpc.Dial("tcp", serverAddr)
-				if err != nil {
-					log.Println("Erro ao conectar servidor:", err)
-					continue
-				}
-				var reply shared.CommandReply
-				cmd := shared.Command{
-					ClientID:      clientID,
-					Sequence:      uint64(time.Now().UnixNano()),
-					ReportedX:     pos[0],
-					ReportedY:     pos[1],
-					CommandString: "UPDATE_POSITION",
-				}
-				// chamada correta ao servidor
-				err = conn.Call("GameServer.SendCommand", cmd, &reply)
-				if err != nil {
-					log.Println("Erro RPC:", err)
-				}
-				conn.Close()
-			default:
-				time.Sleep(100 * time.Millisecond)
-			}
-		}
-	}()
+// isClosedConnError trata como fechamento normal os erros de quando o jogo
+// fecha logo após enviar (em Windows pode vir wsarecv/aborted).
+func isClosedConnError(err error) bool {
+	es := strings.ToLower(err.Error())
+	return strings.Contains(es, "wsarecv") ||
+		strings.Contains(es, "aborted") ||
+		strings.Contains(es, "reset") ||
+		strings.Contains(es, "closed")
 }
 
-// StartLocalStateBroadcaster starts a TCP server to stream game state locally to the UI.
-func (c *Client) StartLocalStateBroadcaster(addr string) error {
-	ln, err := net.Listen("tcp", addr)
-	if err != nil {
-		return err
+// answerHello responde ao Hello do jogo num canal local. Retorna false (e envia
+// um Error com o motivo) quando a versão do jogo não é compatível.
+func (c *Client) answerHello(enc *protocol.Encoder, h *protocol.Hello) bool {
+	if err := shared.CheckLocalVersion(h.Version); err != nil {
+		logf("[CLIENT %s] local peer rejected: %v\n", c.name, err)
+		_ = enc.Encode(&protocol.Error{Message: err.Error()})
+		return false
 	}
-	c.stateLn = ln
-	fmt.Printf("[CLIENT %s] Local state broadcaster on %s\n", c.name, addr)
-	go func() {
-		for {
-			conn, err := ln.Accept()
-			if err != nil {
-				return
-			}
-			c.subsMu.Lock()
-			c.subs[conn] = struct{}{}
-			c.subsMu.Unlock()
-			go c.handleSub(conn)
-		}
-	}()
-	return nil
+	return enc.Encode(protocol.NewHello()) == nil
 }
 
-func (c *Client) handleSub(conn net.Conn) {
-	defer func() {
-		c.subsMu.Lock()
-		delete(c.subs, conn)
-		c.subsMu.Unlock()
-		conn.Close()
-	}()
-	// keep the connection open until closed by peer
-	buf := make([]byte, 1)
-	for {
-		conn.SetReadDeadline(time.Now().Add(5 * time.Minute))
-		if _, err := conn.Read(buf); err != nil {
-			return
-		}
-	}
+// ID returns this client's server-assigned id
+func (c *Client) ID() string {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	return c.clientID
 }
 
-func (c *Client) broadcastState(gs shared.GameState) {
-	c.subsMu.Lock()
-	defer c.subsMu.Unlock()
-	if len(c.subs) == 0 {
-		return
-	}
-	// Build message
-	var b strings.Builder
-	fmt.Fprintf(&b, "SELF %s\n", c.clientID)
-	if n := len(gs.MapLines); n > 0 {
-		fmt.Fprintf(&b, "MAP %d\n", n)
-		for _, line := range gs.MapLines {
-			b.WriteString(line)
-			b.WriteByte('\n')
-		}
-	} else {
-		b.WriteString("MAP 0\n")
-	}
-	fmt.Fprintf(&b, "PLAYERS %d\n", len(gs.Players))
-	for _, p := range gs.Players {
-		fmt.Fprintf(&b, "%s\t%s\t%d\t%d\n", p.ID, p.Name, p.X, p.Y)
-	}
-	b.WriteString("END\n")
-	msg := b.String()
-	// Send to all subs, remove closed
-	for conn := range c.subs {
-		conn.SetWriteDeadline(time.Now().Add(100 * time.Millisecond))
-		if _, err := conn.Write([]byte(msg)); err != nil {
-			// drop
-			delete(c.subs, conn)
-			conn.Close()
-		}
-	}
+// hasFeature consulta as funcionalidades negociadas na conexão atual
+func (c *Client) hasFeature(f string) bool {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	return shared.HasFeature(c.features, f)
 }
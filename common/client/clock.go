@@ -0,0 +1,112 @@
+// clock.go - latência (RTT) e diferença de relógio em relação ao servidor
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"jogo/common/shared"
+)
+
+// amostras mantidas para o filtro de menor RTT (como no NTP)
+const clockSamples = 8
+
+type clockSample struct {
+	rtt    time.Duration
+	offset time.Duration
+}
+
+// clockSync estima RTT e offset (relógio do servidor - relógio local).
+// A estimativa usa a amostra de menor RTT entre as últimas clockSamples,
+// que é a menos afetada por filas na rede.
+type clockSync struct {
+	mu      sync.Mutex
+	samples []clockSample
+	rtt     time.Duration
+	offset  time.Duration
+	valid   bool
+}
+
+// add registra uma troca t0 (envio local), t1 (recepção no servidor),
+// t2 (envio do servidor), t3 (recepção local).
+func (cs *clockSync) add(t0, t1, t2, t3 time.Time) {
+	rtt := t3.Sub(t0) - t2.Sub(t1)
+	if rtt < 0 {
+		rtt = 0
+	}
+	offset := (t1.Sub(t0) + t2.Sub(t3)) / 2
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.samples = append(cs.samples, clockSample{rtt: rtt, offset: offset})
+	if len(cs.samples) > clockSamples {
+		cs.samples = cs.samples[len(cs.samples)-clockSamples:]
+	}
+	best := cs.samples[0]
+	for _, s := range cs.samples[1:] {
+		if s.rtt < best.rtt {
+			best = s
+		}
+	}
+	// RTT exibido é o mais recente; offset vem da melhor amostra
+	cs.rtt = rtt
+	cs.offset = best.offset
+	cs.valid = true
+}
+
+func (cs *clockSync) get() (rtt, offset time.Duration, ok bool) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.rtt, cs.offset, cs.valid
+}
+
+// Ping faz uma medição e atualiza as estimativas.
+func (c *Client) Ping() error {
+	if !c.hasFeature(shared.FeaturePing) {
+		return fmt.Errorf("server does not support %q", shared.FeaturePing)
+	}
+	var rep shared.PingReply
+	t0 := time.Now()
+	if err := c.call("GameServer.Ping", shared.PingArgs{ClientID: c.ID(), ClientSend: t0}, &rep); err != nil {
+		return err
+	}
+	t3 := time.Now()
+	c.clock.add(t0, rep.ServerRecv, rep.ServerSend, t3)
+	return nil
+}
+
+// StartPinger mede a latência periodicamente. Servidores sem "ping" são ignorados.
+func (c *Client) StartPinger(interval time.Duration) {
+	if !c.hasFeature(shared.FeaturePing) {
+		logf("[CLIENT %s] server has no ping support; latency unknown\n", c.name)
+		return
+	}
+	go func() {
+		for {
+			if err := c.Ping(); err != nil && !errors.Is(err, ErrOffline) {
+				logf("[CLIENT %s] Ping error: %v\n", c.name, err)
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// RTT retorna o último round-trip medido (ok=false antes da primeira medição).
+func (c *Client) RTT() (time.Duration, bool) {
+	rtt, _, ok := c.clock.get()
+	return rtt, ok
+}
+
+// ClockOffset retorna relógio do servidor - relógio local.
+func (c *Client) ClockOffset() (time.Duration, bool) {
+	_, off, ok := c.clock.get()
+	return off, ok
+}
+
+// ServerNow estima o horário atual do servidor.
+func (c *Client) ServerNow() time.Time {
+	_, off, _ := c.clock.get()
+	return time.Now().Add(off)
+}
@@ -0,0 +1,146 @@
+// conn.go - gerenciamento da conexão RPC: detecção de queda e reconexão com backoff
+package client
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/rpc"
+	"time"
+
+	"jogo/common/shared"
+)
+
+// Limites do backoff exponencial de reconexão
+const (
+	reconnectMinBackoff = 200 * time.Millisecond
+	reconnectMaxBackoff = 10 * time.Second
+)
+
+// ErrOffline é devolvido enquanto a conexão com o servidor está sendo refeita.
+var ErrOffline = errors.New("client offline: reconnecting")
+
+// dialRPC abre a conexão RPC, com TLS quando configurado
+func dialRPC(addr string, tlsCfg *tls.Config) (*rpc.Client, error) {
+	if tlsCfg == nil {
+		return rpc.Dial("tcp", addr)
+	}
+	conn, err := tls.Dial("tcp", addr, tlsCfg)
+	if err != nil {
+		return nil, err
+	}
+	return rpc.NewClient(conn), nil
+}
+
+// register faz o Register (ou a retomada, se resumeID != "") numa conexão nova.
+func (c *Client) register(conn *rpc.Client, resumeID string) (shared.RegisterReply, error) {
+	var rr shared.RegisterReply
+	args := shared.RegisterArgs{
+		Name:     c.name,
+		Version:  shared.ProtocolVersion,
+		Features: shared.SupportedFeatures,
+		ResumeID: resumeID,
+	}
+	if err := conn.Call("GameServer.Register", args, &rr); err != nil {
+		return rr, fmt.Errorf("register failed (client protocol v%d): %w", shared.ProtocolVersion, err)
+	}
+	// servidor antigo não envia versão (0 = v1)
+	if err := shared.CheckVersion(rr.Version); err != nil {
+		return rr, fmt.Errorf("server incompatible: %w", err)
+	}
+	return rr, nil
+}
+
+// isConnError distingue falhas de transporte de erros devolvidos pelo servidor.
+func isConnError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var se rpc.ServerError
+	if errors.As(err, &se) {
+		return false
+	}
+	var ne net.Error
+	return errors.Is(err, rpc.ErrShutdown) || errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &ne)
+}
+
+// call executa um RPC na conexão atual. Falhas de transporte disparam a
+// reconexão em segundo plano; enquanto ela não termina, devolve ErrOffline.
+func (c *Client) call(method string, args, reply interface{}) error {
+	c.connMu.Lock()
+	rc, online := c.rpcClient, c.online
+	c.connMu.Unlock()
+	if !online {
+		return ErrOffline
+	}
+	err := rc.Call(method, args, reply)
+	if isConnError(err) {
+		c.connectionLost(rc, err)
+	}
+	return err
+}
+
+// Online informa se a conexão com o servidor está ativa.
+func (c *Client) Online() bool {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	return c.online
+}
+
+// connectionLost marca o cliente offline (uma vez por conexão) e inicia a reconexão.
+func (c *Client) connectionLost(rc *rpc.Client, cause error) {
+	c.connMu.Lock()
+	if c.rpcClient != rc || !c.online {
+		c.connMu.Unlock()
+		return
+	}
+	c.online = false
+	c.connMu.Unlock()
+
+	rc.Close()
+	logf("[CLIENT %s] connection lost: %v\n", c.name, cause)
+	c.broadcastStatus("reconnecting…")
+	c.events.emit(Event{Kind: EventDisconnected, Time: time.Now(), Err: cause})
+	go c.reconnectLoop()
+}
+
+// reconnectLoop redisca com backoff exponencial e jitter até conseguir,
+// retomando o clientID quando o servidor ainda o conhece.
+func (c *Client) reconnectLoop() {
+	backoff := reconnectMinBackoff
+	for attempt := 1; ; attempt++ {
+		// jitter: espera entre backoff/2 e backoff
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		time.Sleep(wait)
+
+		conn, err := dialRPC(c.addr, c.tlsCfg)
+		if err == nil {
+			var rr shared.RegisterReply
+			rr, err = c.register(conn, c.ID())
+			if err == nil {
+				c.connMu.Lock()
+				c.rpcClient = conn
+				c.clientID = rr.ClientID
+				c.features = shared.NegotiateFeatures(rr.Version, rr.Features)
+				c.online = true
+				c.connMu.Unlock()
+				logf("[CLIENT %s] reconnected after %d attempts: id=%s resumed=%v\n",
+					c.name, attempt, rr.ClientID, rr.Resumed)
+				c.broadcastStatus("online")
+				c.events.emit(Event{Kind: EventReconnected, Time: time.Now()})
+				c.outbox.wake() // comandos retidos na fila saem agora
+				return
+			}
+			conn.Close()
+		}
+		logf("[CLIENT %s] reconnect attempt %d failed: %v (next in ~%s)\n", c.name, attempt, err, backoff)
+		backoff *= 2
+		if backoff > reconnectMaxBackoff {
+			backoff = reconnectMaxBackoff
+		}
+	}
+}
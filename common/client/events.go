@@ -0,0 +1,268 @@
+// events.go - eventos tipados para quem embute o Client (diferença entre snapshots)
+package client
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"jogo/common/shared"
+)
+
+// EventKind identifica o tipo de um Event.
+type EventKind string
+
+const (
+	EventPlayerJoined    EventKind = "player_joined"
+	EventPlayerLeft      EventKind = "player_left"
+	EventPlayerMoved     EventKind = "player_moved"
+	EventMapChanged      EventKind = "map_changed"
+	EventCommandRejected EventKind = "command_rejected"
+	EventDisconnected    EventKind = "disconnected"
+	EventReconnected     EventKind = "reconnected"
+)
+
+// Event descreve uma mudança observada pelo client. Só os campos do tipo
+// correspondente são preenchidos.
+type Event struct {
+	Kind   EventKind
+	Time   time.Time
+	Player shared.PlayerState // joined, left, moved (posição nova)
+	Prev   shared.PlayerState // moved: posição anterior
+	Map    []string           // map_changed
+	Cmd    shared.Command     // command_rejected
+	Reason string             // command_rejected
+	Err    error              // disconnected
+}
+
+// Eventos aguardando entrega. Acima disso os mais antigos são descartados.
+const eventQueueMax = 1024
+
+// eventHub compara snapshots consecutivos e entrega os eventos, na ordem, por
+// uma única goroutine: um callback lento atrasa os próximos, mas nunca o client.
+// Enquanto espera, movimentos seguidos do mesmo jogador viram um só.
+type eventHub struct {
+	mu      sync.Mutex
+	subs    map[int]func(Event)
+	nextID  int
+	queue   []Event
+	notify  chan struct{}
+	last    map[string]shared.PlayerState
+	lastMap string
+	lastAt  time.Time
+	seen    bool // já houve um snapshot
+}
+
+func newEventHub() *eventHub {
+	h := &eventHub{subs: make(map[int]func(Event)), notify: make(chan struct{}, 1)}
+	go h.run()
+	return h
+}
+
+func (h *eventHub) subscribe(f func(Event)) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	id := h.nextID
+	h.nextID++
+	h.subs[id] = f
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, id)
+			h.mu.Unlock()
+		})
+	}
+}
+
+// emit enfileira eventos; chamar sem h.mu.
+func (h *eventHub) emit(evs ...Event) {
+	h.mu.Lock()
+	h.enqueueLocked(evs)
+	h.mu.Unlock()
+}
+
+func (h *eventHub) enqueueLocked(evs []Event) {
+	if len(evs) == 0 || len(h.subs) == 0 {
+		return
+	}
+	for _, ev := range evs {
+		if ev.Kind != EventPlayerMoved || !h.mergeMovedLocked(ev) {
+			h.queue = append(h.queue, ev)
+		}
+	}
+	if n := len(h.queue); n > eventQueueMax {
+		h.queue = append([]Event(nil), h.queue[n-eventQueueMax:]...)
+	}
+	select {
+	case h.notify <- struct{}{}:
+	default:
+	}
+}
+
+// mergeMovedLocked junta ev ao último evento ainda não entregue do mesmo
+// jogador, se for um movimento: fica a posição de antes do primeiro e a nova.
+func (h *eventHub) mergeMovedLocked(ev Event) bool {
+	for i := len(h.queue) - 1; i >= 0; i-- {
+		q := &h.queue[i]
+		if q.Player.ID != ev.Player.ID {
+			continue
+		}
+		if q.Kind != EventPlayerMoved {
+			return false // entrou ou saiu depois: mantém a ordem
+		}
+		q.Player, q.Time = ev.Player, ev.Time
+		return true
+	}
+	return false
+}
+
+func (h *eventHub) run() {
+	for range h.notify {
+		for {
+			h.mu.Lock()
+			if len(h.queue) == 0 {
+				h.mu.Unlock()
+				break
+			}
+			ev := h.queue[0]
+			h.queue = h.queue[1:]
+			subs := make([]func(Event), 0, len(h.subs))
+			for _, f := range h.subs {
+				subs = append(subs, f)
+			}
+			h.mu.Unlock()
+			for _, f := range subs {
+				f(ev)
+			}
+		}
+	}
+}
+
+// observe gera os eventos da diferença entre gs e o snapshot anterior. O
+// primeiro snapshot anuncia o mapa e todos os jogadores já presentes.
+func (h *eventHub) observe(gs shared.GameState) {
+	now := time.Now()
+	var evs []Event
+	h.mu.Lock()
+	if h.seen && gs.Time.Before(h.lastAt) {
+		h.mu.Unlock()
+		return // resposta atrasada de uma chamada concorrente
+	}
+	h.seen, h.lastAt = true, gs.Time
+	if key := strings.Join(gs.MapLines, "\n"); len(gs.MapLines) > 0 && key != h.lastMap {
+		h.lastMap = key
+		evs = append(evs, Event{Kind: EventMapChanged, Time: now, Map: gs.MapLines})
+	}
+	cur := make(map[string]shared.PlayerState, len(gs.Players))
+	for _, p := range gs.Players {
+		cur[p.ID] = p
+		prev, ok := h.last[p.ID]
+		switch {
+		case !ok:
+			evs = append(evs, Event{Kind: EventPlayerJoined, Time: now, Player: p})
+		case prev.X != p.X || prev.Y != p.Y:
+			evs = append(evs, Event{Kind: EventPlayerMoved, Time: now, Player: p, Prev: prev})
+		}
+	}
+	for id, p := range h.last {
+		if _, ok := cur[id]; !ok {
+			evs = append(evs, Event{Kind: EventPlayerLeft, Time: now, Player: p})
+		}
+	}
+	h.last = cur
+	h.enqueueLocked(evs) // ainda travado: snapshots concorrentes não trocam de ordem
+	h.mu.Unlock()
+}
+
+// Subscribe recebe todos os eventos. Os callbacks rodam um por vez numa
+// goroutine do client (um callback lento recebe movimentos fundidos e, com
+// eventQueueMax pendentes, perde os mais antigos); a função devolvida cancela a inscrição. Inscreva-se
+// antes de StartPolling para receber o mapa e os jogadores iniciais.
+func (c *Client) Subscribe(f func(Event)) (cancel func()) {
+	return c.events.subscribe(f)
+}
+
+// Events é a forma em canal do Subscribe. Com o canal cheio o evento é
+// descartado para não travar os demais inscritos; cancel fecha o canal.
+func (c *Client) Events(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+	var mu sync.Mutex
+	closed := false
+	unsub := c.events.subscribe(func(ev Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		select {
+		case ch <- ev:
+		default:
+		}
+	})
+	return ch, func() {
+		unsub()
+		mu.Lock()
+		defer mu.Unlock()
+		if !closed {
+			closed = true
+			close(ch)
+		}
+	}
+}
+
+// OnPlayerJoined é chamado quando um jogador aparece no estado da sala.
+func (c *Client) OnPlayerJoined(f func(p shared.PlayerState)) (cancel func()) {
+	return c.Subscribe(func(ev Event) {
+		if ev.Kind == EventPlayerJoined {
+			f(ev.Player)
+		}
+	})
+}
+
+// OnPlayerLeft é chamado quando um jogador some do estado da sala.
+func (c *Client) OnPlayerLeft(f func(p shared.PlayerState)) (cancel func()) {
+	return c.Subscribe(func(ev Event) {
+		if ev.Kind == EventPlayerLeft {
+			f(ev.Player)
+		}
+	})
+}
+
+// OnPlayerMoved é chamado quando a posição de um jogador muda entre snapshots.
+func (c *Client) OnPlayerMoved(f func(prev, cur shared.PlayerState)) (cancel func()) {
+	return c.Subscribe(func(ev Event) {
+		if ev.Kind == EventPlayerMoved {
+			f(ev.Prev, ev.Player)
+		}
+	})
+}
+
+// OnMapChanged é chamado com o mapa inicial e a cada troca de mapa.
+func (c *Client) OnMapChanged(f func(lines []string)) (cancel func()) {
+	return c.Subscribe(func(ev Event) {
+		if ev.Kind == EventMapChanged {
+			f(ev.Map)
+		}
+	})
+}
+
+// OnCommandRejected é chamado quando um comando da fila de saída é recusado
+// pelo servidor ou perdido após as tentativas.
+func (c *Client) OnCommandRejected(f func(cmd shared.Command, reason string)) (cancel func()) {
+	return c.Subscribe(func(ev Event) {
+		if ev.Kind == EventCommandRejected {
+			f(ev.Cmd, ev.Reason)
+		}
+	})
+}
+
+// OnDisconnected é chamado quando a conexão com o servidor cai (a reconexão
+// segue automática; ver EventReconnected).
+func (c *Client) OnDisconnected(f func(err error)) (cancel func()) {
+	return c.Subscribe(func(ev Event) {
+		if ev.Kind == EventDisconnected {
+			f(ev.Err)
+		}
+	})
+}
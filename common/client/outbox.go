@@ -0,0 +1,186 @@
+// outbox.go - fila de saída assíncrona de comandos para o servidor
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"jogo/common/protocol"
+	"jogo/common/shared"
+)
+
+// Comandos não-MOVE acima deste limite são recusados (MOVEs sempre coalescem).
+const outboxMaxDepth = 256
+
+// OutboxStats descreve a fila de saída.
+type OutboxStats struct {
+	Depth     int    // comandos aguardando envio
+	Coalesced uint64 // MOVEs substituídos por um mais novo antes do envio
+	Sent      uint64 // comandos aplicados pelo servidor
+	Rejected  uint64 // comandos recusados pelo servidor (Applied=false)
+	Failed    uint64 // comandos descartados após esgotar as tentativas
+	Dropped   uint64 // comandos recusados por fila cheia
+}
+
+// outbox guarda os comandos na ordem de chegada. MOVEs consecutivos colapsam
+// no mais recente; os demais comandos nunca são reordenados nem descartados
+// (exceto com a fila cheia).
+type outbox struct {
+	mu     sync.Mutex
+	items  []queuedCommand
+	notify chan struct{}
+	stats  OutboxStats
+}
+
+// queuedCommand é um comando na fila com a sequência do jogo que o originou
+// (0 se o jogo não informou), devolvida nas confirmações ACK/NACK.
+type queuedCommand struct {
+	cmd shared.Command
+	tag uint64
+}
+
+func newOutbox() *outbox {
+	return &outbox{notify: make(chan struct{}, 1)}
+}
+
+func (o *outbox) wake() {
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+}
+
+// push enfileira cmd. Retorna false se a fila estiver cheia.
+func (o *outbox) push(qc queuedCommand) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if n := len(o.items); n > 0 && qc.cmd.CommandString == "MOVE" && o.items[n-1].cmd.CommandString == "MOVE" {
+		o.items[n-1] = qc
+		o.stats.Coalesced++
+		o.wake()
+		return true
+	}
+	if qc.cmd.CommandString != "MOVE" && len(o.items) >= outboxMaxDepth {
+		o.stats.Dropped++
+		return false
+	}
+	o.items = append(o.items, qc)
+	o.wake()
+	return true
+}
+
+// pop remove e devolve o próximo comando.
+func (o *outbox) pop() (queuedCommand, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.items) == 0 {
+		return queuedCommand{}, false
+	}
+	qc := o.items[0]
+	o.items = o.items[1:]
+	return qc, true
+}
+
+func (o *outbox) count(f func(*OutboxStats)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	f(&o.stats)
+}
+
+// Enqueue coloca um comando na fila de saída sem bloquear. A sequência é
+// atribuída no envio, então MOVEs coalescidos não abrem buracos na numeração.
+// tag é a sequência do jogo (0 = sem predição) e volta nas linhas ACK/NACK.
+func (c *Client) Enqueue(command string, x, y int, tag uint64) error {
+	c.mu.Lock()
+	c.x = x
+	c.y = y
+	c.mu.Unlock()
+	if !c.outbox.push(queuedCommand{cmd: shared.Command{ReportedX: x, ReportedY: y, CommandString: command}, tag: tag}) {
+		c.events.emit(Event{Kind: EventCommandRejected, Time: time.Now(),
+			Cmd: shared.Command{ClientID: c.ID(), ReportedX: x, ReportedY: y, CommandString: command}, Reason: "queue full"})
+		c.broadcastAck(tag, false, "queue full", x, y)
+		return errors.New("outbound queue full")
+	}
+	return nil
+}
+
+// OutboxStats devolve profundidade e contadores da fila de saída.
+func (c *Client) OutboxStats() OutboxStats {
+	c.outbox.mu.Lock()
+	defer c.outbox.mu.Unlock()
+	st := c.outbox.stats
+	st.Depth = len(c.outbox.items)
+	return st
+}
+
+// runOutbox é o único remetente de comandos. O comando em voo sai da fila (não
+// coalesce mais) e, se a conexão cair, é reenviado com a mesma sequência após
+// reconectar, para o servidor descartar uma eventual duplicata.
+func (c *Client) runOutbox() {
+	var inflight *queuedCommand
+	resend := false
+	for {
+		if inflight == nil {
+			qc, ok := c.outbox.pop()
+			if !ok {
+				<-c.outbox.notify
+				continue
+			}
+			c.mu.Lock()
+			c.seq++
+			qc.cmd.Sequence = c.seq
+			c.mu.Unlock()
+			inflight, resend = &qc, false
+		}
+		if !c.Online() {
+			select {
+			case <-c.outbox.notify:
+			case <-time.After(200 * time.Millisecond):
+			}
+			continue
+		}
+		cmd, tag := inflight.cmd, inflight.tag
+		cmd.ClientID = c.ID()
+
+		rep, err := c.sendCommandWithRetry(cmd)
+		if errors.Is(err, ErrOffline) {
+			resend = true
+			continue
+		}
+		inflight = nil
+		// reenvio após queda: "duplicate" significa que o original chegou
+		if resend && err == nil && !rep.Applied && rep.Error == "duplicate or old sequence" {
+			rep.Applied, rep.Error = true, ""
+		}
+		switch {
+		case err != nil:
+			c.outbox.count(func(s *OutboxStats) { s.Failed++ })
+			c.events.emit(Event{Kind: EventCommandRejected, Time: time.Now(), Cmd: cmd, Reason: err.Error()})
+			c.broadcastAck(tag, false, err.Error(), cmd.ReportedX, cmd.ReportedY)
+		case !rep.Applied:
+			c.outbox.count(func(s *OutboxStats) { s.Rejected++ })
+			c.events.emit(Event{Kind: EventCommandRejected, Time: time.Now(), Cmd: cmd, Reason: rep.Error})
+			c.broadcastAck(tag, false, rep.Error, cmd.ReportedX, cmd.ReportedY)
+		default:
+			c.outbox.count(func(s *OutboxStats) { s.Sent++ })
+			// lembra qual sequência do servidor corresponde a qual do jogo (SELFPOS)
+			c.mu.Lock()
+			c.lastAckSeq, c.lastAckTag = cmd.Sequence, tag
+			c.mu.Unlock()
+			c.broadcastAck(tag, true, "", cmd.ReportedX, cmd.ReportedY)
+		}
+	}
+}
+
+// broadcastAck informa o jogo do resultado de um comando, identificado pela
+// sequência do jogo.
+func (c *Client) broadcastAck(tag uint64, applied bool, reason string, x, y int) {
+	if !applied {
+		if reason == "" {
+			reason = "rejected"
+		}
+		logf("[CLIENT %s] command (game seq=%d) rejected: %s\n", c.name, tag, reason)
+	}
+	c.broadcast(&protocol.Ack{Seq: tag, X: x, Y: y, Applied: applied, Reason: reason})
+}
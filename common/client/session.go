@@ -0,0 +1,246 @@
+// session.go - sessão local persistente com o jogo: estado e comandos na mesma conexão
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"jogo/common/protocol"
+	"jogo/common/shared"
+)
+
+// Mensagens aguardando escrita por sessão. Um jogo que não consome nesse ritmo
+// é desconectado; ele reconecta e recebe um snapshot novo.
+const sessionQueue = 128
+
+// localSession é a ligação com um jogo. Todas as mensagens passam pela fila
+// out, então chegam na ordem de envio. Sessões TCP têm um writer que as
+// codifica na conexão; no modo embutido o próprio jogo lê a fila.
+type localSession struct {
+	conn      net.Conn // nil no modo embutido (InProcessSession)
+	out       chan protocol.Message
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newLocalSession(conn net.Conn) *localSession {
+	return &localSession{conn: conn, out: make(chan protocol.Message, sessionQueue), done: make(chan struct{})}
+}
+
+// send enfileira uma mensagem sem bloquear. Retorna false com a fila cheia.
+func (s *localSession) send(m protocol.Message) bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+	}
+	select {
+	case s.out <- m:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *localSession) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	})
+}
+
+func (s *localSession) String() string {
+	if s.conn == nil {
+		return "in-process"
+	}
+	return s.conn.RemoteAddr().String()
+}
+
+func (s *localSession) writeLoop() {
+	enc := protocol.NewEncoder(s.conn)
+	for {
+		select {
+		case m := <-s.out:
+			s.conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
+			if err := enc.Encode(m); err != nil {
+				s.close()
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// addSession registra a sessão e anuncia a versão antes de qualquer snapshot.
+func (c *Client) addSession(s *localSession) {
+	s.send(protocol.NewHello())
+	c.subsMu.Lock()
+	c.subs[s] = struct{}{}
+	c.subsMu.Unlock()
+}
+
+func (c *Client) removeSession(s *localSession) {
+	c.subsMu.Lock()
+	delete(c.subs, s)
+	c.subsMu.Unlock()
+	s.close()
+}
+
+// StartLocalStateBroadcaster aceita as sessões dos jogos locais: o client envia
+// estado, confirmações e status; o jogo envia seus movimentos pela mesma conexão.
+// addr é host:porta ou "unix:/caminho" (ver protocol.Listen).
+func (c *Client) StartLocalStateBroadcaster(addr string) error {
+	ln, err := protocol.Listen(addr)
+	if err != nil {
+		return err
+	}
+	c.stateLn = ln
+	logf("[CLIENT %s] Local game sessions on %s\n", c.name, addr)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s := newLocalSession(conn)
+			c.addSession(s)
+			go s.writeLoop()
+			go c.handleSession(s)
+		}
+	}()
+	return nil
+}
+
+// handleSession lê as mensagens do jogo até a conexão fechar. Sem prazo de
+// leitura: o jogo de um jogador parado não envia nada, e um jogo travado é
+// detectado pela escrita dos snapshots (prazo em writeLoop, fila em broadcast).
+func (c *Client) handleSession(s *localSession) {
+	defer c.removeSession(s)
+	dec := protocol.NewDecoder(s.conn)
+	for {
+		msg, err := dec.Decode()
+		if errors.Is(err, shared.ErrVersionMismatch) {
+			// jogo v2: responde no formato de texto que ele entende
+			logf("[CLIENT %s] game UI rejected: %v\n", c.name, err)
+			s.conn.SetWriteDeadline(time.Now().Add(100 * time.Millisecond))
+			fmt.Fprintf(s.conn, "ERROR %v\n", err)
+		}
+		if err != nil {
+			return
+		}
+		if err := c.handleGameMessage(msg); err != nil {
+			c.subsMu.Lock()
+			delete(c.subs, s)
+			c.subsMu.Unlock()
+			// escreve direto: a sessão fecha logo em seguida
+			line, _ := protocol.Marshal(&protocol.Error{Message: err.Error()})
+			s.conn.SetWriteDeadline(time.Now().Add(100 * time.Millisecond))
+			s.conn.Write(line)
+			return
+		}
+	}
+}
+
+// handleGameMessage trata uma mensagem vinda do jogo. Um erro encerra a sessão.
+func (c *Client) handleGameMessage(msg protocol.Message) error {
+	switch m := msg.(type) {
+	case *protocol.Hello:
+		if err := shared.CheckLocalVersion(m.Version); err != nil {
+			logf("[CLIENT %s] game UI rejected: %v\n", c.name, err)
+			return err
+		}
+	case *protocol.Move:
+		// a confirmação chega depois como Ack, na ordem dos comandos
+		_ = c.Enqueue("MOVE", m.X, m.Y, m.Seq)
+	default:
+		// mensagem desconhecida: ignorar
+	}
+	return nil
+}
+
+// InProcessSession liga o client a um jogo no mesmo processo: as mensagens
+// trafegam por canais, sem TCP nem codificação.
+type InProcessSession struct {
+	c *Client
+	s *localSession
+}
+
+// OpenInProcessSession abre uma sessão embutida. O primeiro item de Messages
+// é o Hello do client, como numa sessão TCP.
+func (c *Client) OpenInProcessSession() *InProcessSession {
+	s := newLocalSession(nil)
+	c.addSession(s)
+	return &InProcessSession{c: c, s: s}
+}
+
+// Messages entrega estado, confirmações e status na ordem de envio.
+func (p *InProcessSession) Messages() <-chan protocol.Message { return p.s.out }
+
+// Done é fechado quando a sessão termina (Close ou jogo lento demais).
+func (p *InProcessSession) Done() <-chan struct{} { return p.s.done }
+
+// Send entrega uma mensagem do jogo ao client (ex.: *protocol.Move).
+func (p *InProcessSession) Send(m protocol.Message) error {
+	if err := p.c.handleGameMessage(m); err != nil {
+		p.Close()
+		return err
+	}
+	return nil
+}
+
+func (p *InProcessSession) Close() { p.c.removeSession(p.s) }
+
+func (c *Client) broadcastState(gs shared.GameState) {
+	c.subsMu.Lock()
+	n := len(c.subs)
+	c.subsMu.Unlock()
+	if n == 0 {
+		return
+	}
+	st := &protocol.State{
+		Self:        c.ID(),
+		Time:        gs.Time,
+		Map:         gs.MapLines,
+		Players:     gs.Players,
+		Queue:       c.OutboxStats().Depth,
+		ItemRespawn: gs.ItemRespawn,
+	}
+	if rtt, off, ok := c.clock.get(); ok {
+		st.Ping = &protocol.Ping{RTT: rtt, Offset: off}
+	}
+	// posição autoritativa do próprio jogador, só quando o snapshot reflete
+	// exatamente o último comando confirmado
+	c.mu.Lock()
+	ackSeq, ackTag := c.lastAckSeq, c.lastAckTag
+	c.mu.Unlock()
+	for _, p := range gs.Players {
+		if p.ID == st.Self && ackTag != 0 && p.LastSeq == ackSeq {
+			st.Confirmed = &protocol.Ack{Seq: ackTag, X: p.X, Y: p.Y, Applied: true}
+		}
+	}
+	c.broadcast(st)
+}
+
+// broadcastStatus envia um Status (ex.: reconnecting…) aos jogos conectados.
+func (c *Client) broadcastStatus(msg string) {
+	c.broadcast(&protocol.Status{Text: msg})
+}
+
+// broadcast envia uma mensagem do protocolo local a todas as sessões.
+func (c *Client) broadcast(m protocol.Message) {
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+	for s := range c.subs {
+		if !s.send(m) {
+			logf("[CLIENT %s] game session too slow, disconnecting %s\n", c.name, s)
+			delete(c.subs, s)
+			s.close()
+		}
+	}
+}
@@ -1,3 +1,29 @@
 package client
 
-// Placeholder for client package-level shared helpers (intentionally empty).
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Client package-level shared helpers.
+
+var (
+	logMu  sync.Mutex
+	logOut io.Writer = os.Stdout
+)
+
+// SetLogOutput redireciona os logs do client (padrão: stdout). O jogo com o
+// client embutido usa io.Discard para não sujar a tela do termbox.
+func SetLogOutput(w io.Writer) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	logOut = w
+}
+
+func logf(format string, args ...interface{}) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	fmt.Fprintf(logOut, format, args...)
+}
@@ -0,0 +1,104 @@
+// messages.go - mensagens do protocolo local
+package protocol
+
+import (
+	"time"
+
+	"jogo/common/shared"
+)
+
+func init() {
+	Register(func() Message { return &Hello{} })
+	Register(func() Message { return &Error{} })
+	Register(func() Message { return &Move{} })
+	Register(func() Message { return &OK{} })
+	Register(func() Message { return &State{} })
+	Register(func() Message { return &Status{} })
+	Register(func() Message { return &Ack{} })
+	Register(func() Message { return &Chat{} })
+}
+
+// Hello abre os dois canais (jogo -> client e client -> jogo).
+type Hello struct {
+	Version  int      `json:"version"`
+	Features []string `json:"features,omitempty"`
+}
+
+func (*Hello) MessageType() string { return "hello" }
+
+// NewHello anuncia a versão e as funcionalidades deste build.
+func NewHello() *Hello {
+	return &Hello{Version: shared.ProtocolVersion, Features: shared.SupportedFeatures}
+}
+
+// Error recusa a conexão (ex.: versão incompatível); o remetente fecha em seguida.
+type Error struct {
+	Message string `json:"message"`
+}
+
+func (*Error) MessageType() string { return "error" }
+
+// Move é a posição do jogador após um movimento. Seq é a sequência do jogo
+// (0 = sem predição), devolvida em Ack e State.Confirmed.
+type Move struct {
+	X   int    `json:"x"`
+	Y   int    `json:"y"`
+	Seq uint64 `json:"seq,omitempty"`
+}
+
+func (*Move) MessageType() string { return "move" }
+
+// OK confirma o recebimento de um Move pelo client (não pelo servidor).
+type OK struct{}
+
+func (*OK) MessageType() string { return "ok" }
+
+// State é o snapshot periódico enviado ao jogo.
+type State struct {
+	Self    string               `json:"self"`
+	Time    time.Time            `json:"time"` // horário do snapshot no servidor
+	Map     []string             `json:"map,omitempty"`
+	Players []shared.PlayerState `json:"players"`
+	Queue   int                  `json:"queue"`          // comandos na fila de saída
+	Ping    *Ping                `json:"ping,omitempty"` // nil até a primeira medição
+	// posição autoritativa do jogador após o último Move confirmado, quando
+	// o snapshot já o reflete
+	Confirmed *Ack `json:"confirmed,omitempty"`
+	// tempo até um item coletado reaparecer (chaves shared.ItemStar, ...);
+	// item ausente não reaparece
+	ItemRespawn map[string]time.Duration `json:"item_respawn,omitempty"`
+}
+
+func (*State) MessageType() string { return "state" }
+
+// Ping traz a latência e a diferença de relógio em relação ao servidor.
+type Ping struct {
+	RTT    time.Duration `json:"rtt"`
+	Offset time.Duration `json:"offset"`
+}
+
+// Status informa mudanças na conexão com o servidor ("online", "reconnecting…").
+type Status struct {
+	Text string `json:"text"`
+}
+
+func (*Status) MessageType() string { return "status" }
+
+// Ack é o resultado de um Move no servidor.
+type Ack struct {
+	Seq     uint64 `json:"seq"`
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	Applied bool   `json:"applied"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+func (*Ack) MessageType() string { return "ack" }
+
+// Chat é uma mensagem de texto para o jogador.
+type Chat struct {
+	From string `json:"from,omitempty"`
+	Text string `json:"text"`
+}
+
+func (*Chat) MessageType() string { return "chat" }
@@ -0,0 +1,156 @@
+// protocol.go - protocolo local entre o jogo e o cmd/client
+//
+// Cada mensagem é uma linha JSON {"type": "...", "data": {...}}. Novos tipos só
+// precisam de uma struct com MessageType() e de uma chamada a Register; peers
+// antigos recebem *Unknown e podem ignorá-la.
+package protocol
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"jogo/common/shared"
+)
+
+// MaxMessageSize limita uma linha (o snapshot com o mapa é a maior mensagem).
+const MaxMessageSize = 1024 * 1024
+
+// ErrMalformed indica uma linha que não é uma mensagem válida (ex.: peer com o
+// protocolo de texto antigo), diferente de um erro de conexão.
+var ErrMalformed = errors.New("protocol: malformed message")
+
+// Message é qualquer mensagem do protocolo local.
+type Message interface {
+	MessageType() string
+}
+
+// Unknown é devolvida pelo Decoder para tipos não registrados.
+type Unknown struct {
+	Type string
+	Data json.RawMessage
+}
+
+func (m *Unknown) MessageType() string { return m.Type }
+
+type envelope struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]func() Message{}
+)
+
+// Register associa um tipo de mensagem ao construtor usado na decodificação.
+func Register(newMsg func() Message) {
+	t := newMsg().MessageType()
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[t]; dup {
+		panic("protocol: duplicate message type " + t)
+	}
+	registry[t] = newMsg
+}
+
+// Marshal codifica m como uma linha (com '\n'), para enviar a vários peers.
+func Marshal(m Message) ([]byte, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("protocol: encode %s: %w", m.MessageType(), err)
+	}
+	line, err := json.Marshal(envelope{Type: m.MessageType(), Data: data})
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// Encoder escreve mensagens numa conexão. Seguro para uso concorrente.
+type Encoder struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode escreve uma ou mais mensagens numa única escrita.
+func (e *Encoder) Encode(msgs ...Message) error {
+	var buf []byte
+	for _, m := range msgs {
+		line, err := Marshal(m)
+		if err != nil {
+			return err
+		}
+		buf = append(buf, line...)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := e.w.Write(buf)
+	return err
+}
+
+// Decoder lê mensagens linha a linha.
+type Decoder struct {
+	sc *bufio.Scanner
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), MaxMessageSize)
+	return &Decoder{sc: sc}
+}
+
+// Decode devolve a próxima mensagem, io.EOF no fim da conexão ou um erro se a
+// linha não for uma mensagem válida. O "HELLO <versão>" do protocolo de texto
+// antigo vira um erro de versão (shared.ErrVersionMismatch).
+func (d *Decoder) Decode() (Message, error) {
+	for d.sc.Scan() {
+		line := d.sc.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if v, ok := legacyHello(line); ok {
+			return nil, shared.CheckLocalVersion(v)
+		}
+		var env envelope
+		if err := json.Unmarshal(line, &env); err != nil || env.Type == "" {
+			return nil, fmt.Errorf("%w %.40q", ErrMalformed, line)
+		}
+		registryMu.RLock()
+		newMsg, ok := registry[env.Type]
+		registryMu.RUnlock()
+		if !ok {
+			return &Unknown{Type: env.Type, Data: append(json.RawMessage(nil), env.Data...)}, nil
+		}
+		m := newMsg()
+		if len(env.Data) > 0 {
+			if err := json.Unmarshal(env.Data, m); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrMalformed, env.Type, err)
+			}
+		}
+		return m, nil
+	}
+	if err := d.sc.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// legacyHello reconhece a linha "HELLO <versão> [feature ...]" dos peers v2.
+func legacyHello(line []byte) (version int, ok bool) {
+	parts := strings.Fields(string(line))
+	if len(parts) < 2 || !strings.EqualFold(parts[0], "HELLO") {
+		return 0, false
+	}
+	v, err := strconv.Atoi(parts[1])
+	return v, err == nil
+}
@@ -0,0 +1,97 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"jogo/common/shared"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	agora := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	msgs := []Message{
+		NewHello(),
+		&Error{Message: "protocol version mismatch"},
+		&Move{X: 3, Y: 4, Seq: 7},
+		&Move{X: 1, Y: 2}, // sem predição
+		&OK{},
+		&State{
+			Self:        "C000001",
+			Time:        agora,
+			Map:         []string{"▤▤▤", "▤☺▤"},
+			Players:     []shared.PlayerState{{ID: "C000001", Name: "ana", X: 1, Y: 1, LastSeq: 9, Seen: agora}},
+			Queue:       2,
+			Ping:        &Ping{RTT: 20 * time.Millisecond, Offset: -time.Second},
+			Confirmed:   &Ack{Seq: 7, X: 1, Y: 1, Applied: true},
+			ItemRespawn: map[string]time.Duration{shared.ItemStar: 30 * time.Second},
+		},
+		&Status{Text: "reconnecting…"},
+		&Ack{Seq: 8, X: 2, Y: 1, Reason: "rate limited"},
+		&Chat{From: "ana", Text: "oi"},
+	}
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(msgs...); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "\n"); n != len(msgs) {
+		t.Fatalf("expected one line per message, got %d lines for %d messages", n, len(msgs))
+	}
+	dec := NewDecoder(&buf)
+	for _, want := range msgs {
+		got, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("decode %s: %v", want.MessageType(), err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("round trip of %s:\n got %#v\nwant %#v", want.MessageType(), got, want)
+		}
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Fatalf("expected io.EOF after the last message, got %v", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		nome    string
+		entrada string
+		want    Message
+		wantErr error
+	}{
+		{"tipo desconhecido", `{"type":"future","data":{"a":1}}` + "\n",
+			&Unknown{Type: "future", Data: json.RawMessage(`{"a":1}`)}, nil},
+		{"desconhecido sem dados", `{"type":"future"}` + "\n", &Unknown{Type: "future"}, nil},
+		{"linhas vazias ignoradas", "\n\n" + `{"type":"ok"}` + "\n", &OK{}, nil},
+		{"sem dados", `{"type":"move"}` + "\n", &Move{}, nil},
+		{"texto antigo", "MOVE 1 2\n", nil, ErrMalformed},
+		{"sem tipo", `{"data":{}}` + "\n", nil, ErrMalformed},
+		{"dados inválidos", `{"type":"move","data":{"x":"a"}}` + "\n", nil, ErrMalformed},
+		{"HELLO v2", "HELLO 2 map_lines hello\n", nil, shared.ErrVersionMismatch},
+		{"HELLO v1", "hello 1\n", nil, shared.ErrVersionMismatch},
+		{"fim", "", nil, io.EOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nome, func(t *testing.T) {
+			got, err := NewDecoder(strings.NewReader(tt.entrada)).Decode()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected error %v, got %v (message %#v)", tt.wantErr, err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
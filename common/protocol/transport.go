@@ -0,0 +1,100 @@
+// transport.go - endereços locais: TCP (host:porta) ou socket unix ("unix:/caminho")
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const unixPrefix = "unix:"
+
+// splitAddr converte "unix:/caminho" em ("unix", "/caminho"); o resto é TCP.
+func splitAddr(addr string) (network, address string) {
+	if strings.HasPrefix(addr, unixPrefix) {
+		return "unix", strings.TrimPrefix(addr, unixPrefix)
+	}
+	return "tcp", addr
+}
+
+// Listen abre um endereço local. Sockets unix ficam com permissão 0600 (só o
+// dono injeta comandos) desde o primeiro instante: são criados num diretório
+// 0700 e só então movidos para path. Um arquivo deixado por um processo morto
+// é removido.
+func Listen(addr string) (net.Listener, error) {
+	network, path := splitAddr(addr)
+	if network == "tcp" {
+		return net.Listen("tcp", addr)
+	}
+	if path == "" {
+		return nil, fmt.Errorf("empty unix socket path in %q", addr)
+	}
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".sock")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	tmp := filepath.Join(dir, "s")
+	ln, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	ul := ln.(*net.UnixListener)
+	ul.SetUnlinkOnClose(false) // o arquivo muda de nome; unixListener.Close apaga path
+	if err := os.Chmod(tmp, 0o600); err != nil && runtime.GOOS != "windows" {
+		ul.Close()
+		return nil, fmt.Errorf("restrict permissions of %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		ul.Close()
+		return nil, err
+	}
+	return &unixListener{UnixListener: ul, path: path}, nil
+}
+
+// unixListener apaga o socket de Listen ao fechar.
+type unixListener struct {
+	*net.UnixListener
+	path string
+}
+
+func (l *unixListener) Close() error {
+	err := l.UnixListener.Close()
+	if err == nil {
+		os.Remove(l.path)
+	}
+	return err
+}
+
+// removeStaleSocket apaga path se for um socket sem ninguém escutando.
+func removeStaleSocket(path string) error {
+	fi, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, 200*time.Millisecond); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is in use by another process", path)
+	}
+	return os.Remove(path)
+}
+
+// Dial conecta a um endereço local aceito por Listen.
+func Dial(addr string, timeout time.Duration) (net.Conn, error) {
+	network, address := splitAddr(addr)
+	return net.DialTimeout(network, address, timeout)
+}
@@ -0,0 +1,93 @@
+// admin.go - interface HTTP de administração (protegida por token)
+package server
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// StartAdminServer serves the admin API on addr. Every request must carry
+// "Authorization: Bearer <token>".
+//
+//	GET /admin/incidents  -> []Incident (detecções do anti-cheat)
+//	GET /admin/anticheat  -> AntiCheatConfig em vigor
+//	GET /admin/config     -> Config em vigor
+//	POST /admin/reload    -> relê o arquivo de configuração
+func StartAdminServer(gs *GameServer, addr, token string, tlsCfg *tls.Config) (*http.Server, error) {
+	if token == "" {
+		return nil, errors.New("admin: a token is required")
+	}
+	listener, err := listen(addr, tlsCfg)
+	if err != nil {
+		return nil, err
+	}
+	hs := &http.Server{
+		Handler:           requireToken(token, gs.adminHandler()),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	fmt.Printf("[SERVER] Admin interface listening on %s\n", addr)
+	go func() {
+		if err := hs.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("[SERVER] admin server error: %v\n", err)
+		}
+	}()
+	return hs, nil
+}
+
+func (gs *GameServer) adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/incidents", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSONError(w, http.StatusMethodNotAllowed, "use GET")
+			return
+		}
+		writeJSON(w, http.StatusOK, gs.Incidents())
+	})
+	mux.HandleFunc("/admin/anticheat", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSONError(w, http.StatusMethodNotAllowed, "use GET")
+			return
+		}
+		writeJSON(w, http.StatusOK, gs.AntiCheatConfig())
+	})
+	mux.HandleFunc("/admin/config", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSONError(w, http.StatusMethodNotAllowed, "use GET")
+			return
+		}
+		writeJSON(w, http.StatusOK, gs.Config())
+	})
+	mux.HandleFunc("/admin/reload", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSONError(w, http.StatusMethodNotAllowed, "use POST")
+			return
+		}
+		warnings, err := gs.Reload()
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if warnings == nil {
+			warnings = []string{}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"reloaded": true, "warnings": warnings})
+	})
+	return mux
+}
+
+func requireToken(token string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(strings.TrimSpace(r.Header.Get("Authorization")))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			writeJSONError(w, http.StatusUnauthorized, "missing or invalid admin token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,221 @@
+// anticheat.go - detecção de speed-hack e anomalias de tempo nos comandos
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Ações possíveis quando um cliente excede os limites
+const (
+	AntiCheatFlag     = "flag"     // só registra o incidente
+	AntiCheatThrottle = "throttle" // registra e descarta o comando
+	AntiCheatKick     = "kick"     // registra e remove o jogador da sala
+)
+
+// AntiCheatConfig define os limites de uma sala (cada GameServer é uma sala).
+type AntiCheatConfig struct {
+	MaxMoveRate float64 `json:"max_move_rate"` // MOVEs por segundo sustentados; 0 desliga
+	Burst       int     `json:"burst"`         // rajada tolerada acima da taxa
+	MaxSeqGap   uint64  `json:"max_seq_gap"`   // salto máximo de Sequence entre comandos; 0 desliga
+	MaxStep     int     `json:"max_step"`      // células por MOVE (Chebyshev); 0 desliga
+	Action      string  `json:"action"`        // flag, throttle ou kick
+	KickAfter   int     `json:"kick_after"`    // violações antes do kick (ação kick); zera após antiCheatCleanWindow sem violações
+}
+
+// DefaultAntiCheatConfig é folgado o bastante para o loop do termbox com auto-repeat.
+func DefaultAntiCheatConfig() AntiCheatConfig {
+	return AntiCheatConfig{
+		MaxMoveRate: 15,
+		Burst:       10,
+		MaxSeqGap:   50,
+		MaxStep:     0,
+		Action:      AntiCheatFlag,
+		KickAfter:   5,
+	}
+}
+
+// Validate confere a configuração.
+func (c AntiCheatConfig) Validate() error {
+	switch c.Action {
+	case AntiCheatFlag, AntiCheatThrottle, AntiCheatKick:
+	default:
+		return fmt.Errorf("anticheat: action must be %q, %q or %q, got %q",
+			AntiCheatFlag, AntiCheatThrottle, AntiCheatKick, c.Action)
+	}
+	if c.MaxMoveRate < 0 || c.Burst < 0 || c.MaxStep < 0 || c.KickAfter < 0 {
+		return fmt.Errorf("anticheat: limits must not be negative")
+	}
+	if c.MaxMoveRate > 0 && c.Burst < 1 {
+		return fmt.Errorf("anticheat: burst must be at least 1 when max_move_rate is set")
+	}
+	return nil
+}
+
+// Incident registra uma detecção com as evidências coletadas.
+type Incident struct {
+	Time      time.Time       `json:"time"`
+	ClientID  string          `json:"client_id"`
+	Name      string          `json:"name"`
+	Kind      string          `json:"kind"`   // rate, seq_gap, step
+	Action    string          `json:"action"` // ação aplicada
+	Detail    string          `json:"detail"`
+	Sequence  uint64          `json:"sequence"`
+	Intervals []time.Duration `json:"intervals_ns"` // intervalos entre os últimos comandos
+}
+
+const (
+	antiCheatHistory = 16  // timestamps guardados por cliente
+	maxIncidentsKept = 200 // incidentes mantidos em memória para o admin
+	// sem violações por esse tempo a contagem para o kick recomeça, para uma
+	// rajada ocasional de um jogador legítimo não somar para sempre
+	antiCheatCleanWindow = time.Minute
+)
+
+// moveTracker guarda o histórico recente de um cliente.
+type moveTracker struct {
+	tokens     float64
+	lastRefill time.Time
+	recent     []time.Time
+	violations int
+	lastViol   time.Time // última violação (ver antiCheatCleanWindow)
+}
+
+// antiCheat é protegido pelo mutex do GameServer, exceto incidents que tem o seu.
+type antiCheat struct {
+	cfg      AntiCheatConfig
+	trackers map[string]*moveTracker
+
+	incMu     sync.Mutex
+	incidents []Incident
+}
+
+func newAntiCheat(cfg AntiCheatConfig) *antiCheat {
+	return &antiCheat{cfg: cfg, trackers: make(map[string]*moveTracker)}
+}
+
+// check avalia um comando novo (sequência já validada). Retorna a ação a
+// aplicar ("" se nenhum limite foi violado).
+func (ac *antiCheat) check(clientID, name string, seq, lastSeq uint64, from, to [2]int, now time.Time) string {
+	t := ac.trackers[clientID]
+	if t == nil {
+		t = &moveTracker{tokens: float64(ac.cfg.Burst), lastRefill: now}
+		ac.trackers[clientID] = t
+	}
+	t.recent = append(t.recent, now)
+	if len(t.recent) > antiCheatHistory {
+		t.recent = t.recent[len(t.recent)-antiCheatHistory:]
+	}
+
+	var kind, detail string
+	if ac.cfg.MaxMoveRate > 0 {
+		t.tokens += now.Sub(t.lastRefill).Seconds() * ac.cfg.MaxMoveRate
+		if t.tokens > float64(ac.cfg.Burst) {
+			t.tokens = float64(ac.cfg.Burst)
+		}
+		t.lastRefill = now
+		if t.tokens < 1 {
+			kind = "rate"
+			detail = fmt.Sprintf("%.1f moves/s observed, limit %.1f (burst %d)",
+				observedRate(t.recent), ac.cfg.MaxMoveRate, ac.cfg.Burst)
+		} else {
+			t.tokens--
+		}
+	}
+	if kind == "" && ac.cfg.MaxSeqGap > 0 && lastSeq > 0 && seq-lastSeq > ac.cfg.MaxSeqGap {
+		kind = "seq_gap"
+		detail = fmt.Sprintf("sequence jumped from %d to %d (limit %d)", lastSeq, seq, ac.cfg.MaxSeqGap)
+	}
+	// o primeiro comando sai da posição inicial do servidor, não conta
+	if kind == "" && ac.cfg.MaxStep > 0 && lastSeq > 0 {
+		if step := chebyshev(from, to); step > ac.cfg.MaxStep {
+			kind = "step"
+			detail = fmt.Sprintf("moved %d cells from (%d,%d) to (%d,%d) (limit %d)",
+				step, from[0], from[1], to[0], to[1], ac.cfg.MaxStep)
+		}
+	}
+	if kind == "" {
+		return ""
+	}
+
+	if now.Sub(t.lastViol) > antiCheatCleanWindow {
+		t.violations = 0
+	}
+	t.violations++
+	t.lastViol = now
+	action := ac.cfg.Action
+	if action == AntiCheatKick && t.violations < ac.cfg.KickAfter {
+		action = AntiCheatThrottle
+	}
+	inc := Incident{
+		Time:      now,
+		ClientID:  clientID,
+		Name:      name,
+		Kind:      kind,
+		Action:    action,
+		Detail:    detail,
+		Sequence:  seq,
+		Intervals: intervals(t.recent),
+	}
+	ac.record(inc)
+	fmt.Printf("[SERVER] ANTICHEAT %s client=%s name=%s action=%s seq=%d: %s intervals=%v\n",
+		kind, clientID, name, action, seq, detail, inc.Intervals)
+	return action
+}
+
+// forget descarta o histórico de um cliente removido.
+func (ac *antiCheat) forget(clientID string) {
+	delete(ac.trackers, clientID)
+}
+
+func (ac *antiCheat) record(inc Incident) {
+	ac.incMu.Lock()
+	defer ac.incMu.Unlock()
+	ac.incidents = append(ac.incidents, inc)
+	if len(ac.incidents) > maxIncidentsKept {
+		ac.incidents = ac.incidents[len(ac.incidents)-maxIncidentsKept:]
+	}
+}
+
+// Incidents devolve uma cópia dos incidentes mais recentes (mais antigo primeiro).
+func (gs *GameServer) Incidents() []Incident {
+	gs.anticheat.incMu.Lock()
+	defer gs.anticheat.incMu.Unlock()
+	out := make([]Incident, len(gs.anticheat.incidents))
+	copy(out, gs.anticheat.incidents)
+	return out
+}
+
+func observedRate(ts []time.Time) float64 {
+	if len(ts) < 2 {
+		return 0
+	}
+	span := ts[len(ts)-1].Sub(ts[0]).Seconds()
+	if span <= 0 {
+		return float64(len(ts))
+	}
+	return float64(len(ts)-1) / span
+}
+
+func intervals(ts []time.Time) []time.Duration {
+	out := make([]time.Duration, 0, len(ts))
+	for i := 1; i < len(ts); i++ {
+		out = append(out, ts[i].Sub(ts[i-1]))
+	}
+	return out
+}
+
+func chebyshev(a, b [2]int) int {
+	dx, dy := a[0]-b[0], a[1]-b[1]
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	if dx > dy {
+		return dx
+	}
+	return dy
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+// comando é uma chamada a antiCheat.check, em ms desde o início do teste.
+type comando struct {
+	ms           int
+	seq, lastSeq uint64
+	from, to     [2]int
+	esperado     string // ação devolvida ("" = nenhum limite violado)
+}
+
+func TestAntiCheatCheck(t *testing.T) {
+	base := AntiCheatConfig{Action: AntiCheatThrottle, KickAfter: 3}
+	com := func(f func(*AntiCheatConfig)) AntiCheatConfig {
+		c := base
+		f(&c)
+		return c
+	}
+	tests := []struct {
+		nome     string
+		cfg      AntiCheatConfig
+		comandos []comando
+	}{
+		{"rajada dentro do burst", com(func(c *AntiCheatConfig) { c.MaxMoveRate, c.Burst = 10, 3 }), []comando{
+			{ms: 0, seq: 1}, {ms: 0, seq: 2, lastSeq: 1}, {ms: 0, seq: 3, lastSeq: 2},
+		}},
+		{"acima do burst", com(func(c *AntiCheatConfig) { c.MaxMoveRate, c.Burst = 10, 2 }), []comando{
+			{ms: 0, seq: 1}, {ms: 0, seq: 2, lastSeq: 1},
+			{ms: 0, seq: 3, lastSeq: 2, esperado: AntiCheatThrottle},
+			{ms: 100, seq: 4, lastSeq: 3}, // um token a 10/s
+			{ms: 100, seq: 5, lastSeq: 4, esperado: AntiCheatThrottle},
+		}},
+		{"taxa desligada", com(func(c *AntiCheatConfig) { c.MaxMoveRate, c.Burst = 0, 0 }), []comando{
+			{ms: 0, seq: 1}, {ms: 0, seq: 2, lastSeq: 1}, {ms: 0, seq: 3, lastSeq: 2},
+		}},
+		{"salto de sequência", com(func(c *AntiCheatConfig) { c.MaxSeqGap = 5 }), []comando{
+			{ms: 0, seq: 40},               // primeiro comando não tem referência
+			{ms: 10, seq: 45, lastSeq: 40}, // salto igual ao limite
+			{ms: 20, seq: 51, lastSeq: 45, esperado: AntiCheatThrottle},
+		}},
+		{"passo grande", com(func(c *AntiCheatConfig) { c.MaxStep = 1 }), []comando{
+			{ms: 0, seq: 1, to: [2]int{5, 5}}, // sai da posição inicial do servidor
+			{ms: 10, seq: 2, lastSeq: 1, from: [2]int{5, 5}, to: [2]int{6, 6}},
+			{ms: 20, seq: 3, lastSeq: 2, from: [2]int{6, 6}, to: [2]int{8, 6}, esperado: AntiCheatThrottle},
+		}},
+		{"kick após kick_after", com(func(c *AntiCheatConfig) { c.MaxSeqGap, c.Action = 1, AntiCheatKick }), []comando{
+			{ms: 0, seq: 3, lastSeq: 1, esperado: AntiCheatThrottle},
+			{ms: 10, seq: 5, lastSeq: 3, esperado: AntiCheatThrottle},
+			{ms: 20, seq: 7, lastSeq: 5, esperado: AntiCheatKick},
+		}},
+		{"contagem zera após janela limpa", com(func(c *AntiCheatConfig) { c.MaxSeqGap, c.Action = 1, AntiCheatKick }), []comando{
+			{ms: 0, seq: 3, lastSeq: 1, esperado: AntiCheatThrottle},
+			{ms: 10, seq: 5, lastSeq: 3, esperado: AntiCheatThrottle},
+			{ms: 10 + int(antiCheatCleanWindow/time.Millisecond) + 1, seq: 7, lastSeq: 5, esperado: AntiCheatThrottle},
+		}},
+	}
+	inicio := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.nome, func(t *testing.T) {
+			ac := newAntiCheat(tt.cfg)
+			for i, c := range tt.comandos {
+				now := inicio.Add(time.Duration(c.ms) * time.Millisecond)
+				if got := ac.check("C1", "ana", c.seq, c.lastSeq, c.from, c.to, now); got != c.esperado {
+					t.Fatalf("command %d (seq %d): got action %q, want %q", i, c.seq, got, c.esperado)
+				}
+			}
+		})
+	}
+}
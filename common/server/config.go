@@ -0,0 +1,371 @@
+// config.go - arquivo de configuração JSON do servidor e recarga das regras
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"jogo/common/shared"
+)
+
+// Políticas de posição inicial de um jogador recém-registrado
+const (
+	SpawnOrigin = "origin" // (0,0), comportamento original
+	SpawnMap    = "map"    // primeiro ☺ do mapa ativo
+	SpawnRandom = "random" // célula livre aleatória do mapa ativo
+)
+
+// Itens com tempo de reaparecimento configurável
+var respawnableItems = []string{shared.ItemStar, shared.ItemInvisibility}
+
+// Duration aceita "30s", "2m" etc. no JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %v", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// LimitsConfig agrupa os limites da sala.
+type LimitsConfig struct {
+	MaxPlayers    int             `json:"max_players"`     // 0 = sem limite
+	MaxNameLength int             `json:"max_name_length"` // 0 = sem limite
+	IdleTimeout   Duration        `json:"idle_timeout"`    // sem comando nem GetState por mais que isso o jogador sai da sala; 0 = nunca
+	AntiCheat     AntiCheatConfig `json:"anticheat"`
+}
+
+// Config é o conteúdo do arquivo passado em --config.
+type Config struct {
+	Listen      string              `json:"listen"`
+	Maps        []string            `json:"maps"`         // rotação de mapas; o primeiro abre a partida
+	TickRate    float64             `json:"tick_rate_hz"` // relógio de rodadas e do feed de espectadores
+	Limits      LimitsConfig        `json:"limits"`
+	SpawnPolicy string              `json:"spawn_policy"`
+	ItemRespawn map[string]Duration `json:"item_respawn"` // item coletado reaparece após; ausente ou 0 = não reaparece
+	RoundLength Duration            `json:"round_length"` // 0 = partida sem rodadas
+}
+
+// DefaultConfig reproduz o comportamento do servidor sem arquivo de configuração.
+func DefaultConfig() Config {
+	return Config{
+		Listen:   "0.0.0.0:12345",
+		Maps:     []string{"mapa.txt"},
+		TickRate: 2,
+		Limits: LimitsConfig{
+			MaxNameLength: 32,
+			IdleTimeout:   Duration(time.Minute),
+			AntiCheat:     DefaultAntiCheatConfig(),
+		},
+		SpawnPolicy: SpawnOrigin,
+		ItemRespawn: map[string]Duration{},
+	}
+}
+
+// LoadConfig lê e valida um arquivo de configuração. Campos ausentes ficam com
+// os valores de DefaultConfig; campos desconhecidos são erro.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		var se *json.SyntaxError
+		var te *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &se):
+			return cfg, fmt.Errorf("%s:%d: %v", path, lineOf(data, se.Offset), err)
+		case errors.As(err, &te):
+			return cfg, fmt.Errorf("%s:%d: field %q: expected %s, got %s", path, lineOf(data, te.Offset), te.Field, te.Type, te.Value)
+		default:
+			return cfg, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate reporta todos os problemas encontrados de uma vez.
+func (c Config) Validate() error {
+	var errs []error
+	if strings.TrimSpace(c.Listen) == "" {
+		errs = append(errs, errors.New("listen must not be empty"))
+	}
+	if len(c.Maps) == 0 {
+		errs = append(errs, errors.New("maps must list at least one map file"))
+	}
+	for i, m := range c.Maps {
+		if _, err := os.Stat(m); err != nil {
+			errs = append(errs, fmt.Errorf("maps[%d]: %v", i, err))
+		}
+	}
+	if c.TickRate <= 0 || c.TickRate > 100 {
+		errs = append(errs, fmt.Errorf("tick_rate_hz must be in (0, 100], got %v", c.TickRate))
+	}
+	if c.Limits.MaxPlayers < 0 {
+		errs = append(errs, errors.New("limits.max_players must not be negative"))
+	}
+	if c.Limits.MaxNameLength < 0 {
+		errs = append(errs, errors.New("limits.max_name_length must not be negative"))
+	}
+	if c.Limits.IdleTimeout < 0 {
+		errs = append(errs, errors.New("limits.idle_timeout must not be negative"))
+	}
+	if err := c.Limits.AntiCheat.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("limits.%v", err))
+	}
+	switch c.SpawnPolicy {
+	case SpawnOrigin, SpawnMap, SpawnRandom:
+	default:
+		errs = append(errs, fmt.Errorf("spawn_policy must be %q, %q or %q, got %q",
+			SpawnOrigin, SpawnMap, SpawnRandom, c.SpawnPolicy))
+	}
+	for item, d := range c.ItemRespawn {
+		known := false
+		for _, k := range respawnableItems {
+			known = known || k == item
+		}
+		if !known {
+			errs = append(errs, fmt.Errorf("item_respawn: unknown item %q (known: %s)", item, strings.Join(respawnableItems, ", ")))
+		}
+		if d < 0 {
+			errs = append(errs, fmt.Errorf("item_respawn.%s must not be negative", item))
+		}
+	}
+	if c.RoundLength < 0 {
+		errs = append(errs, errors.New("round_length must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// lineOf converte um offset de byte em número de linha (1-based).
+func lineOf(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// NewGameServerFromConfig cria o servidor com as regras de cfg. Todos os mapas
+// da rotação precisam carregar.
+func NewGameServerFromConfig(cfg Config) (*GameServer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	maps, err := loadMaps(cfg.Maps)
+	if err != nil {
+		return nil, err
+	}
+	gs := newGameServer(cfg)
+	gs.maps = maps
+	gs.mapLines = maps[0]
+	go gs.runTicker()
+	return gs, nil
+}
+
+// NewGameServerFromFile lê path e guarda o caminho para Reload. override, se
+// não for nil, é reaplicado a cada leitura (flags da linha de comando).
+func NewGameServerFromFile(path string, override func(*Config)) (*GameServer, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if override != nil {
+		override(&cfg)
+	}
+	gs, err := NewGameServerFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	gs.cfgPath = path
+	gs.cfgOverride = override
+	return gs, nil
+}
+
+func loadMaps(paths []string) ([][]string, error) {
+	maps := make([][]string, 0, len(paths))
+	for _, p := range paths {
+		lines, err := loadMapLines(p)
+		if err != nil {
+			return nil, fmt.Errorf("map %s: %v", p, err)
+		}
+		if len(lines) == 0 {
+			return nil, fmt.Errorf("map %s is empty", p)
+		}
+		maps = append(maps, lines)
+	}
+	return maps, nil
+}
+
+// Config devolve a configuração em vigor.
+func (gs *GameServer) Config() Config {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	cfg := gs.cfg
+	cfg.Maps = append([]string(nil), gs.cfg.Maps...)
+	cfg.ItemRespawn = make(map[string]Duration, len(gs.cfg.ItemRespawn))
+	for k, v := range gs.cfg.ItemRespawn {
+		cfg.ItemRespawn[k] = v
+	}
+	return cfg
+}
+
+// ApplyConfig troca as regras recarregáveis sem derrubar jogadores. listen e
+// maps só mudam reiniciando o servidor; diferenças nesses campos são
+// devolvidas como avisos.
+func (gs *GameServer) ApplyConfig(cfg Config) (warnings []string, err error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if cfg.Listen != gs.cfg.Listen {
+		warnings = append(warnings, fmt.Sprintf("listen changed to %q: requires restart, keeping %q", cfg.Listen, gs.cfg.Listen))
+	}
+	if strings.Join(cfg.Maps, "\n") != strings.Join(gs.cfg.Maps, "\n") {
+		warnings = append(warnings, "maps changed: requires restart, keeping current rotation")
+	}
+	cfg.Listen = gs.cfg.Listen
+	cfg.Maps = gs.cfg.Maps
+
+	if cfg.RoundLength != gs.cfg.RoundLength {
+		if cfg.RoundLength > 0 {
+			gs.roundEnds = time.Now().Add(time.Duration(cfg.RoundLength))
+		} else {
+			gs.roundEnds = time.Time{}
+		}
+	}
+	gs.cfg = cfg
+	gs.anticheat.cfg = cfg.Limits.AntiCheat
+	for _, w := range warnings {
+		fmt.Printf("[SERVER] config: %s\n", w)
+	}
+	fmt.Printf("[SERVER] config applied: tick=%.1fHz spawn=%s round=%s max_players=%d\n",
+		cfg.TickRate, cfg.SpawnPolicy, time.Duration(cfg.RoundLength), cfg.Limits.MaxPlayers)
+	return warnings, nil
+}
+
+// Reload relê o arquivo de configuração (SIGHUP ou admin).
+func (gs *GameServer) Reload() ([]string, error) {
+	if gs.cfgPath == "" {
+		return nil, errors.New("server was not started with a config file")
+	}
+	cfg, err := LoadConfig(gs.cfgPath)
+	if err != nil {
+		return nil, err
+	}
+	if gs.cfgOverride != nil {
+		gs.cfgOverride(&cfg)
+	}
+	return gs.ApplyConfig(cfg)
+}
+
+// tickInterval é o período do relógio do servidor.
+func (gs *GameServer) tickInterval() time.Duration {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return time.Duration(float64(time.Second) / gs.cfg.TickRate)
+}
+
+// runTicker avança as rodadas; o período acompanha recargas do tick_rate_hz.
+func (gs *GameServer) runTicker() {
+	for {
+		time.Sleep(gs.tickInterval())
+		gs.tick(time.Now())
+	}
+}
+
+func (gs *GameServer) tick(now time.Time) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.dropIdlePlayers(now)
+	if gs.cfg.RoundLength <= 0 {
+		return
+	}
+	if gs.roundEnds.IsZero() {
+		gs.roundEnds = now.Add(time.Duration(gs.cfg.RoundLength))
+		return
+	}
+	if now.Before(gs.roundEnds) {
+		return
+	}
+	gs.round++
+	if len(gs.maps) > 0 {
+		gs.mapIndex = (gs.mapIndex + 1) % len(gs.maps)
+		gs.mapLines = gs.maps[gs.mapIndex]
+	}
+	gs.roundEnds = now.Add(time.Duration(gs.cfg.RoundLength))
+	fmt.Printf("[SERVER] Round %d started (map %d/%d), ends at %s\n",
+		gs.round, gs.mapIndex+1, len(gs.maps), gs.roundEnds.Format("15:04:05"))
+}
+
+// dropIdlePlayers tira da sala quem não fala com o servidor há mais de
+// limits.idle_timeout; sem isso jogadores desconectados ocupariam vagas de
+// max_players para sempre. Chamar com gs.mu travado.
+func (gs *GameServer) dropIdlePlayers(now time.Time) {
+	timeout := time.Duration(gs.cfg.Limits.IdleTimeout)
+	if timeout <= 0 {
+		return
+	}
+	for id := range gs.players {
+		if now.Sub(gs.seen[id]) > timeout {
+			fmt.Printf("[SERVER] Client %s idle for more than %s\n", id, timeout)
+			gs.removePlayer(id)
+		}
+	}
+}
+
+// spawnPosition escolhe a posição inicial segundo spawn_policy. Chamar com gs.mu travado.
+func (gs *GameServer) spawnPosition() (int, int) {
+	switch gs.cfg.SpawnPolicy {
+	case SpawnMap:
+		for y, line := range gs.mapLines {
+			x := 0
+			for _, ch := range line {
+				if ch == '☺' {
+					return x, y
+				}
+				x++
+			}
+		}
+	case SpawnRandom:
+		var free [][2]int
+		for y, line := range gs.mapLines {
+			x := 0
+			for _, ch := range line {
+				if ch == ' ' || ch == '☺' {
+					free = append(free, [2]int{x, y})
+				}
+				x++
+			}
+		}
+		if len(free) > 0 {
+			p := free[rand.Intn(len(free))]
+			return p[0], p[1]
+		}
+	}
+	return 0, 0
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigValidate(t *testing.T) {
+	mapa := filepath.Join(t.TempDir(), "mapa.txt")
+	if err := os.WriteFile(mapa, []byte("▤▤▤\n▤☺▤\n▤▤▤\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	valida := func(f func(*Config)) Config {
+		c := DefaultConfig()
+		c.Maps = []string{mapa}
+		f(&c)
+		return c
+	}
+	tests := []struct {
+		nome  string
+		cfg   Config
+		erros []string // trechos esperados na mensagem; vazio = válida
+	}{
+		{"padrão", valida(func(c *Config) {}), nil},
+		{"completa", valida(func(c *Config) {
+			c.Limits.MaxPlayers = 8
+			c.SpawnPolicy = SpawnRandom
+			c.ItemRespawn = map[string]Duration{"star": Duration(30 * time.Second), "invisibility": 0}
+			c.RoundLength = Duration(5 * time.Minute)
+		}), nil},
+		{"listen vazio", valida(func(c *Config) { c.Listen = " " }), []string{"listen must not be empty"}},
+		{"sem mapas", valida(func(c *Config) { c.Maps = nil }), []string{"maps must list at least one map file"}},
+		{"mapa inexistente", valida(func(c *Config) { c.Maps = append(c.Maps, "nao-existe.txt") }), []string{"maps[1]"}},
+		{"tick zero", valida(func(c *Config) { c.TickRate = 0 }), []string{"tick_rate_hz"}},
+		{"tick alto", valida(func(c *Config) { c.TickRate = 101 }), []string{"tick_rate_hz"}},
+		{"limites negativos", valida(func(c *Config) {
+			c.Limits.MaxPlayers = -1
+			c.Limits.MaxNameLength = -1
+			c.Limits.IdleTimeout = Duration(-time.Second)
+		}), []string{"limits.max_players", "limits.max_name_length", "limits.idle_timeout"}},
+		{"anticheat inválido", valida(func(c *Config) { c.Limits.AntiCheat.Action = "ban" }), []string{"limits.anticheat: action"}},
+		{"spawn desconhecido", valida(func(c *Config) { c.SpawnPolicy = "corner" }), []string{"spawn_policy"}},
+		{"item desconhecido", valida(func(c *Config) { c.ItemRespawn = map[string]Duration{"sword": 0} }), []string{`unknown item "sword"`}},
+		{"respawn negativo", valida(func(c *Config) { c.ItemRespawn = map[string]Duration{"star": -1} }), []string{"item_respawn.star"}},
+		{"rodada negativa", valida(func(c *Config) { c.RoundLength = -1 }), []string{"round_length"}},
+		{"todos os erros de uma vez", valida(func(c *Config) {
+			c.Listen = ""
+			c.TickRate = -1
+			c.SpawnPolicy = "x"
+		}), []string{"listen", "tick_rate_hz", "spawn_policy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nome, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if len(tt.erros) == 0 {
+				if err != nil {
+					t.Fatalf("expected a valid config, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors mentioning %q", tt.erros)
+			}
+			for _, e := range tt.erros {
+				if !strings.Contains(err.Error(), e) {
+					t.Errorf("error %q does not mention %q", err, e)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// gateway.go - transportes para clientes que não são Go (JSON-RPC e HTTP/JSON)
+package server
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/rpc/jsonrpc"
+	"time"
+
+	"jogo/common/shared"
+)
+
+// StartJSONRPCServer exposes the same GameServer methods over net/rpc/jsonrpc
+// (JSON-RPC 1.0, one JSON object per request on a raw TCP stream).
+func StartJSONRPCServer(gs *GameServer, addr string, tlsCfg *tls.Config) (net.Listener, error) {
+	srv, err := gs.rpcServer()
+	if err != nil {
+		return nil, err
+	}
+	listener, err := listen(addr, tlsCfg)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("[SERVER] JSON-RPC server listening on %s\n", addr)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				fmt.Printf("[SERVER] JSON-RPC accept error: %v\n", err)
+				return
+			}
+			go srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+		}
+	}()
+	return listener, nil
+}
+
+// StartHTTPGateway serves a small HTTP/JSON API on top of gs:
+//
+//	POST /register  {"name": "..."}                          -> RegisterReply
+//	POST /command   {"client_id": "...", "sequence": 1, ...} -> CommandReply
+//	GET  /state?client_id=...                                -> GameState
+//
+// Errors are returned as {"error": "..."} with a 4xx/5xx status.
+func StartHTTPGateway(gs *GameServer, addr string, tlsCfg *tls.Config) (*http.Server, error) {
+	listener, err := listen(addr, tlsCfg)
+	if err != nil {
+		return nil, err
+	}
+	hs := &http.Server{
+		Handler:           gs.httpHandler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	fmt.Printf("[SERVER] HTTP gateway listening on %s\n", addr)
+
+	go func() {
+		if err := hs.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("[SERVER] HTTP gateway error: %v\n", err)
+		}
+	}()
+	return hs, nil
+}
+
+func (gs *GameServer) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSONError(w, http.StatusMethodNotAllowed, "use POST")
+			return
+		}
+		var args shared.RegisterArgs
+		if err := decodeJSONBody(w, r, &args); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		var reply shared.RegisterReply
+		if err := gs.Register(args, &reply); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, reply)
+	})
+
+	mux.HandleFunc("/command", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSONError(w, http.StatusMethodNotAllowed, "use POST")
+			return
+		}
+		var cmd shared.Command
+		if err := decodeJSONBody(w, r, &cmd); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		var reply shared.CommandReply
+		if err := gs.SendCommand(cmd, &reply); err != nil {
+			writeJSONError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, reply)
+	})
+
+	mux.HandleFunc("/state", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSONError(w, http.StatusMethodNotAllowed, "use GET")
+			return
+		}
+		var gsReply shared.GameState
+		args := shared.GetStateArgs{ClientID: r.URL.Query().Get("client_id")}
+		if err := gs.GetState(args, &gsReply); err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, gsReply)
+	})
+
+	return mux
+}
+
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON body: %v", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
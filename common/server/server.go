@@ -2,12 +2,14 @@
 package server
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/rpc"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
@@ -20,10 +22,24 @@ type GameServer struct {
 
 	players map[string]shared.PlayerState // clientID -> PlayerState
 	lastSeq map[string]uint64             // clientID -> last applied sequence number
+	seen    map[string]time.Time          // clientID -> último comando ou GetState
 	names   map[string]string             // clientID -> name
 	nextID  uint64
 
-	mapLines []string // authoritative map as lines
+	mapLines []string   // authoritative map as lines
+	maps     [][]string // rotação de mapas (mapLines = maps[mapIndex])
+	mapIndex int
+
+	cfg         Config        // regras em vigor
+	cfgPath     string        // arquivo para Reload ("" = sem arquivo)
+	cfgOverride func(*Config) // reaplicado a cada Reload
+	round       int           // rodada atual (0 = primeira)
+	roundEnds   time.Time     // fim da rodada; zero = sem rodadas
+	anticheat   *antiCheat    // limites de taxa da sala
+
+	rpcOnce sync.Once
+	rpcSrv  *rpc.Server
+	rpcErr  error
 }
 
 // loadMapLines loads a text map file into a slice of strings.
@@ -72,34 +88,76 @@ func loadMapLines(path string) ([]string, error) {
 	return lines, nil
 }
 
+// NewGameServer creates a server with DefaultConfig (no config file).
 func NewGameServer() *GameServer {
-	gs := &GameServer{
+	gs := newGameServer(DefaultConfig())
+	// Try to load map from local file (mapa.txt); non-fatal if missing
+	if lines, err := loadMapLines("mapa.txt"); err == nil {
+		gs.mapLines = lines
+		gs.maps = [][]string{lines}
+	}
+	go gs.runTicker()
+	return gs
+}
+
+func newGameServer(cfg Config) *GameServer {
+	return &GameServer{
 		players:  make(map[string]shared.PlayerState),
 		lastSeq:  make(map[string]uint64),
+		seen:     make(map[string]time.Time),
 		names:    make(map[string]string),
 		nextID:   1,
 		mapLines: nil,
+
+		cfg:       cfg,
+		anticheat: newAntiCheat(cfg.Limits.AntiCheat),
 	}
-	// Try to load map from local file (mapa.txt); non-fatal if missing
-	if lines, err := loadMapLines("mapa.txt"); err == nil {
-		gs.mapLines = lines
-	}
-	return gs
 }
 
 // Register: client pede um clientID
 func (gs *GameServer) Register(args shared.RegisterArgs, reply *shared.RegisterReply) error {
+	if err := shared.CheckVersion(args.Version); err != nil {
+		fmt.Printf("[SERVER] Register rejected: name=%s: %v\n", args.Name, err)
+		return err
+	}
+
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
 
+	// reconexão: mesmo nome e id ainda conhecido -> retoma estado e sequência
+	if args.ResumeID != "" {
+		if ps, ok := gs.players[args.ResumeID]; ok && ps.Name == args.Name {
+			reply.ClientID = args.ResumeID
+			reply.Version = shared.ProtocolVersion
+			reply.Features = shared.NegotiateFeatures(args.Version, args.Features)
+			reply.Resumed = true
+			gs.seen[args.ResumeID] = time.Now()
+			fmt.Printf("[SERVER] Register resume: name=%s clientID=%s lastSeq=%d\n", args.Name, args.ResumeID, gs.lastSeq[args.ResumeID])
+			return nil
+		}
+	}
+
+	if max := gs.cfg.Limits.MaxNameLength; max > 0 && len([]rune(args.Name)) > max {
+		return fmt.Errorf("name too long (max %d characters)", max)
+	}
+	if max := gs.cfg.Limits.MaxPlayers; max > 0 && len(gs.players) >= max {
+		fmt.Printf("[SERVER] Register rejected: name=%s: server full (%d players)\n", args.Name, max)
+		return fmt.Errorf("server full (%d players)", max)
+	}
+
 	id := fmt.Sprintf("C%06d", gs.nextID)
 	gs.nextID++
+	x, y := gs.spawnPosition()
 	gs.names[id] = args.Name
-	gs.players[id] = shared.PlayerState{ID: id, Name: args.Name, X: 0, Y: 0}
+	gs.players[id] = shared.PlayerState{ID: id, Name: args.Name, X: x, Y: y}
 	gs.lastSeq[id] = 0
+	gs.seen[id] = time.Now()
 
 	reply.ClientID = id
-	fmt.Printf("[SERVER] Register request: name=%s -> clientID=%s\n", args.Name, id)
+	reply.Version = shared.ProtocolVersion
+	reply.Features = shared.NegotiateFeatures(args.Version, args.Features)
+	fmt.Printf("[SERVER] Register request: name=%s v%d -> clientID=%s features=%v\n",
+		args.Name, shared.EffectiveVersion(args.Version), id, reply.Features)
 	return nil
 }
 
@@ -116,6 +174,7 @@ func (gs *GameServer) SendCommand(cmd shared.Command, reply *shared.CommandReply
 		reply.Error = "unknown client"
 		return errors.New(reply.Error)
 	}
+	gs.seen[cmd.ClientID] = time.Now()
 
 	last := gs.lastSeq[cmd.ClientID]
 	if cmd.Sequence <= last {
@@ -126,6 +185,21 @@ func (gs *GameServer) SendCommand(cmd shared.Command, reply *shared.CommandReply
 	}
 
 	ps := gs.players[cmd.ClientID]
+	switch gs.anticheat.check(cmd.ClientID, ps.Name, cmd.Sequence, last,
+		[2]int{ps.X, ps.Y}, [2]int{cmd.ReportedX, cmd.ReportedY}, time.Now()) {
+	case AntiCheatThrottle:
+		// consome a sequência para que o reenvio não seja aplicado depois
+		gs.lastSeq[cmd.ClientID] = cmd.Sequence
+		reply.Applied = false
+		reply.Error = "rate limited"
+		return nil
+	case AntiCheatKick:
+		gs.removePlayer(cmd.ClientID)
+		reply.Applied = false
+		reply.Error = "kicked: move rate exceeded"
+		return errors.New(reply.Error)
+	}
+
 	ps.X = cmd.ReportedX
 	ps.Y = cmd.ReportedY
 	gs.players[cmd.ClientID] = ps
@@ -137,34 +211,133 @@ func (gs *GameServer) SendCommand(cmd shared.Command, reply *shared.CommandReply
 	return nil
 }
 
+// Unregister: cliente sai da sala e libera a vaga (id desconhecido não é erro)
+func (gs *GameServer) Unregister(args shared.UnregisterArgs, reply *shared.UnregisterReply) error {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if _, ok := gs.players[args.ClientID]; ok {
+		gs.removePlayer(args.ClientID)
+	}
+	return nil
+}
+
+// removePlayer tira o cliente da sala. Chamar com gs.mu travado.
+func (gs *GameServer) removePlayer(clientID string) {
+	fmt.Printf("[SERVER] Removing client %s (%s)\n", clientID, gs.names[clientID])
+	delete(gs.players, clientID)
+	delete(gs.lastSeq, clientID)
+	delete(gs.seen, clientID)
+	delete(gs.names, clientID)
+	gs.anticheat.forget(clientID)
+}
+
+// SetAntiCheatConfig troca os limites da sala; o histórico dos clientes é mantido.
+func (gs *GameServer) SetAntiCheatConfig(cfg AntiCheatConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.anticheat.cfg = cfg
+	gs.cfg.Limits.AntiCheat = cfg
+	return nil
+}
+
+// AntiCheatConfig devolve os limites em vigor.
+func (gs *GameServer) AntiCheatConfig() AntiCheatConfig {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return gs.anticheat.cfg
+}
+
 // GetState: cliente pede estado atual do jogo
 func (gs *GameServer) GetState(args shared.GetStateArgs, reply *shared.GameState) error {
+	gs.mu.Lock()
+	if _, ok := gs.players[args.ClientID]; ok {
+		gs.seen[args.ClientID] = time.Now()
+	}
+	gs.mu.Unlock()
+	*reply = gs.snapshot()
+	fmt.Printf("[SERVER] GetState requested by %s -> %d players returned\n", args.ClientID, len(reply.Players))
+	return nil
+}
+
+// Ping: devolve os instantes de recepção e envio do servidor para o cliente
+// estimar RTT e diferença de relógio. Não trava o estado do jogo.
+func (gs *GameServer) Ping(args shared.PingArgs, reply *shared.PingReply) error {
+	reply.ServerRecv = time.Now()
+	reply.ClientSend = args.ClientSend
+	reply.ServerSend = time.Now()
+	return nil
+}
+
+// snapshot copies the current game state under the lock
+func (gs *GameServer) snapshot() shared.GameState {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
 
 	players := make([]shared.PlayerState, 0, len(gs.players))
-	for _, p := range gs.players {
+	for id, p := range gs.players {
+		p.LastSeq = gs.lastSeq[id]
+		p.Seen = gs.seen[id]
 		players = append(players, p)
 	}
-	reply.Players = players
-	reply.Time = time.Now()
-	reply.MapLines = gs.mapLines
+	sort.Slice(players, func(i, k int) bool { return players[i].ID < players[k].ID })
+	respawn := make(map[string]time.Duration, len(gs.cfg.ItemRespawn))
+	for item, d := range gs.cfg.ItemRespawn {
+		respawn[item] = time.Duration(d)
+	}
+	return shared.GameState{
+		Players:     players,
+		Time:        time.Now(),
+		MapLines:    gs.mapLines,
+		Round:       gs.round,
+		RoundEndsAt: gs.roundEnds,
+		ItemRespawn: respawn,
+	}
+}
 
-	fmt.Printf("[SERVER] GetState requested by %s -> %d players returned\n", args.ClientID, len(players))
-	return nil
+// rpcServer returns the *rpc.Server with gs registered as "GameServer".
+// It is shared by every transport (gob, JSON-RPC) so they see the same state.
+func (gs *GameServer) rpcServer() (*rpc.Server, error) {
+	gs.rpcOnce.Do(func() {
+		gs.rpcSrv = rpc.NewServer()
+		gs.rpcErr = gs.rpcSrv.RegisterName("GameServer", gs)
+	})
+	return gs.rpcSrv, gs.rpcErr
+}
+
+// listen opens a TCP listener, wrapped in TLS when tlsCfg is not nil.
+func listen(addr string, tlsCfg *tls.Config) (net.Listener, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if tlsCfg != nil {
+		listener = tls.NewListener(listener, tlsCfg)
+	}
+	return listener, nil
 }
 
-// StartRPCServer starts the RPC server on the given address and returns the listener.
-func StartRPCServer(addr string) (net.Listener, error) {
-	gs := NewGameServer()
-	if err := rpc.Register(gs); err != nil {
+// StartRPCServer starts the gob RPC server for gs on the given address and returns the listener.
+// When tlsCfg is nil the transport is plaintext (local development).
+func StartRPCServer(gs *GameServer, addr string, tlsCfg *tls.Config) (net.Listener, error) {
+	srv, err := gs.rpcServer()
+	if err != nil {
 		return nil, err
 	}
 
-	listener, err := net.Listen("tcp", addr)
+	listener, err := listen(addr, tlsCfg)
 	if err != nil {
 		return nil, err
 	}
+	if tlsCfg != nil {
+		for _, cert := range tlsCfg.Certificates {
+			if len(cert.Certificate) > 0 {
+				fmt.Printf("[SERVER] TLS enabled, certificate sha256=%s\n", shared.CertFingerprint(cert.Certificate[0]))
+			}
+		}
+	}
 	fmt.Printf("[SERVER] RPC server listening on %s\n", addr)
 
 	go func() {
@@ -175,7 +348,7 @@ func StartRPCServer(addr string) (net.Listener, error) {
 				fmt.Printf("[SERVER] accept error: %v\n", err)
 				return
 			}
-			go rpc.ServeConn(conn)
+			go srv.ServeConn(conn)
 		}
 	}()
 
@@ -0,0 +1,165 @@
+// spectator.go - feed somente-leitura para espectadores (Server-Sent Events + página HTML)
+package server
+
+import (
+	"bytes"
+	"crypto/tls"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"jogo/common/shared"
+)
+
+//go:embed spectator.html
+var spectatorPage []byte
+
+// Símbolos do mapa que representam entidades (mesmos do Elemento no jogo)
+const (
+	glyphMonster       = '☠'
+	glyphStar          = '★'
+	glyphInvisibility  = '¤'
+	spectatorHeartbeat = 15 * time.Second
+)
+
+// SpectatorCell is an entity's spawn point on the map grid.
+type SpectatorCell struct {
+	Kind string `json:"kind"` // "monster", "star", "invisibility"
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+}
+
+// SpectatorState is the payload of each "state" event in the SSE stream.
+type SpectatorState struct {
+	Players       []shared.PlayerState `json:"players"`
+	MonsterSpawns []SpectatorCell      `json:"monster_spawns"`
+	ItemSpawns    []SpectatorCell      `json:"item_spawns"`
+	MapLines      []string             `json:"map_lines"`
+	Time          time.Time            `json:"time"`
+}
+
+// spectatorState builds the spectator view. Monsters and items are simulated by
+// each game process, so the server only knows where the map file places them:
+// they are reported as spawn points, not live positions.
+func (gs *GameServer) spectatorState() SpectatorState {
+	snap := gs.snapshot()
+	st := SpectatorState{
+		Players:       snap.Players,
+		MonsterSpawns: []SpectatorCell{},
+		ItemSpawns:    []SpectatorCell{},
+		MapLines:      snap.MapLines,
+		Time:          snap.Time,
+	}
+	for y, line := range snap.MapLines {
+		x := 0
+		for _, ch := range line {
+			switch ch {
+			case glyphMonster:
+				st.MonsterSpawns = append(st.MonsterSpawns, SpectatorCell{Kind: "monster", X: x, Y: y})
+			case glyphStar:
+				st.ItemSpawns = append(st.ItemSpawns, SpectatorCell{Kind: "star", X: x, Y: y})
+			case glyphInvisibility:
+				st.ItemSpawns = append(st.ItemSpawns, SpectatorCell{Kind: "invisibility", X: x, Y: y})
+			}
+			x++
+		}
+	}
+	return st
+}
+
+// StartSpectatorServer serves the spectator page on "/" and the SSE stream on
+// "/events". Spectators are never registered, so they don't count as players
+// and have no way to send commands.
+func StartSpectatorServer(gs *GameServer, addr string, tlsCfg *tls.Config) (*http.Server, error) {
+	listener, err := listen(addr, tlsCfg)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(spectatorPage)
+	})
+	mux.HandleFunc("/events", gs.serveSpectatorEvents)
+
+	hs := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	fmt.Printf("[SERVER] Spectator feed on %s\n", addr)
+	go func() {
+		if err := hs.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("[SERVER] spectator server error: %v\n", err)
+		}
+	}()
+	return hs, nil
+}
+
+func (gs *GameServer) serveSpectatorEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "use GET", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	// um snapshot por tick do servidor (tick_rate_hz)
+	ticker := time.NewTicker(gs.tickInterval())
+	defer ticker.Stop()
+	lastSent := time.Time{}
+	var last []byte
+
+	push := func() bool {
+		st := gs.spectatorState()
+		// compara sem os horários e sequências, que mudam a cada consulta dos
+		// clientes, para só enviar quando algo visível mudou
+		cmp := st
+		cmp.Time = time.Time{}
+		cmp.Players = make([]shared.PlayerState, len(st.Players))
+		for i, p := range st.Players {
+			p.Seen, p.LastSeq = time.Time{}, 0
+			cmp.Players[i] = p
+		}
+		key, err := json.Marshal(cmp)
+		if err != nil {
+			return false
+		}
+		if bytes.Equal(key, last) && time.Since(lastSent) < spectatorHeartbeat {
+			return true
+		}
+		data, err := json.Marshal(st)
+		if err != nil {
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "event: state\ndata: %s\n\n", data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		last = key
+		lastSent = time.Now()
+		return true
+	}
+
+	if !push() {
+		return
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if !push() {
+				return
+			}
+		}
+	}
+}
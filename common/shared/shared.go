@@ -2,43 +2,83 @@ package shared
 
 import "time"
 
-// RPC shared types so client and server agree on gob names
+// RPC shared types so client and server agree on gob names.
+// The json tags define the schema used by the JSON-RPC and HTTP gateways.
 
 type RegisterArgs struct {
-	Name string
+	Name     string   `json:"name"`
+	Version  int      `json:"version,omitempty"`   // 0 = cliente anterior ao handshake (v1)
+	Features []string `json:"features,omitempty"`  // funcionalidades oferecidas pelo cliente
+	ResumeID string   `json:"resume_id,omitempty"` // reconexão: clientID anterior a retomar
 }
 
 type RegisterReply struct {
-	ClientID string
+	ClientID string   `json:"client_id"`
+	Version  int      `json:"version"`  // versão do servidor
+	Features []string `json:"features"` // funcionalidades negociadas
+	Resumed  bool     `json:"resumed"`  // true se ResumeID foi aceito
 }
 
 type Command struct {
-	ClientID      string
-	Sequence      uint64
-	ReportedX     int
-	ReportedY     int
-	CommandString string
+	ClientID      string `json:"client_id"`
+	Sequence      uint64 `json:"sequence"`
+	ReportedX     int    `json:"x"`
+	ReportedY     int    `json:"y"`
+	CommandString string `json:"command"`
 }
 
 type CommandReply struct {
-	Applied bool
-	Error   string
+	Applied bool   `json:"applied"`
+	Error   string `json:"error,omitempty"`
 }
 
 type GetStateArgs struct {
-	ClientID string
+	ClientID string `json:"client_id"`
 }
 
+type UnregisterArgs struct {
+	ClientID string `json:"client_id"`
+}
+
+type UnregisterReply struct{}
+
 type PlayerState struct {
-	ID   string
-	Name string
-	X    int
-	Y    int
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+	// último comando aplicado pelo servidor (reconciliação no cliente)
+	LastSeq uint64 `json:"last_seq"`
+	// último contato do cliente com o servidor (comando ou GetState); zero
+	// em servidores antigos
+	Seen time.Time `json:"seen"`
 }
 
 type GameState struct {
-	Players []PlayerState
-	Time    time.Time
+	Players []PlayerState `json:"players"`
+	Time    time.Time     `json:"time"`
 	// Optional: authoritative map provided by server as lines
-	MapLines []string
+	MapLines []string `json:"map_lines,omitempty"`
+	// Regras da sala (servidores antigos deixam zerado)
+	Round       int                      `json:"round"`
+	RoundEndsAt time.Time                `json:"round_ends_at"` // zero = sem rodadas
+	ItemRespawn map[string]time.Duration `json:"item_respawn_ns,omitempty"`
+}
+
+// Itens do mapa com tempo de reaparecimento (chaves de GameState.ItemRespawn)
+const (
+	ItemStar         = "star"
+	ItemInvisibility = "invisibility"
+)
+
+// Ping: medição de latência e sincronização de relógio (estilo NTP)
+type PingArgs struct {
+	ClientID   string    `json:"client_id"`
+	ClientSend time.Time `json:"client_send"` // t0
+}
+
+type PingReply struct {
+	ClientSend time.Time `json:"client_send"` // t0 ecoado
+	ServerRecv time.Time `json:"server_recv"` // t1
+	ServerSend time.Time `json:"server_send"` // t2
 }
@@ -0,0 +1,174 @@
+package shared
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// TLSOptions descreve a configuração TLS opcional do transporte RPC.
+// Com todos os campos vazios o transporte continua em texto puro.
+type TLSOptions struct {
+	CertFile   string // certificado PEM (servidor)
+	KeyFile    string // chave privada PEM (servidor)
+	CAFile     string // CA para validar o par remoto
+	SelfSigned bool   // servidor: gera certificado autoassinado em memória (LAN)
+	PinSHA256  string // cliente: fingerprint SHA-256 (hex) do certificado do servidor
+	ServerName string // cliente: nome esperado no certificado (padrão: host do endereço)
+}
+
+// Enabled indica se alguma opção TLS foi configurada.
+func (o TLSOptions) Enabled() bool {
+	return o.CertFile != "" || o.KeyFile != "" || o.CAFile != "" || o.SelfSigned || o.PinSHA256 != ""
+}
+
+// ServerTLSConfig monta o *tls.Config do servidor. Retorna nil quando TLS não
+// está habilitado.
+func ServerTLSConfig(o TLSOptions) (*tls.Config, error) {
+	if !o.Enabled() {
+		return nil, nil
+	}
+	var cert tls.Certificate
+	var err error
+	switch {
+	case o.SelfSigned:
+		cert, err = GenerateSelfSignedCert()
+	case o.CertFile != "" && o.KeyFile != "":
+		cert, err = tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+	default:
+		return nil, errors.New("tls: server needs --tls-cert and --tls-key, or --tls-self-signed")
+	}
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if o.CAFile != "" {
+		pool, err := loadCertPool(o.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// ClientTLSConfig monta o *tls.Config do cliente. Retorna nil quando TLS não
+// está habilitado. Com PinSHA256 a cadeia não é validada contra CAs: apenas o
+// fingerprint do certificado folha é comparado (útil com --tls-self-signed).
+func ClientTLSConfig(o TLSOptions) (*tls.Config, error) {
+	if !o.Enabled() {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		ServerName: o.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if o.CAFile != "" {
+		pool, err := loadCertPool(o.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	if o.CertFile != "" && o.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	if o.PinSHA256 != "" {
+		pin := normalizeFingerprint(o.PinSHA256)
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("tls: server sent no certificate")
+			}
+			got := CertFingerprint(rawCerts[0])
+			if got != pin {
+				return fmt.Errorf("tls: certificate pin mismatch: got %s", got)
+			}
+			return nil
+		}
+	}
+	return cfg, nil
+}
+
+// GenerateSelfSignedCert cria um certificado ECDSA autoassinado válido por um
+// ano para localhost e os IPs locais da máquina.
+func GenerateSelfSignedCert() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	host, _ := os.Hostname()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "jogo-server"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1"), net.IPv6loopback},
+	}
+	if host != "" {
+		tmpl.DNSNames = append(tmpl.DNSNames, host)
+	}
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, a := range addrs {
+			if ipn, ok := a.(*net.IPNet); ok && !ipn.IP.IsLoopback() {
+				tmpl.IPAddresses = append(tmpl.IPAddresses, ipn.IP)
+			}
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// CertFingerprint retorna o SHA-256 (hex minúsculo) de um certificado DER.
+func CertFingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeFingerprint aceita "AA:BB:..." ou hex contínuo.
+func normalizeFingerprint(s string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), ":", ""))
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("tls: no certificates found in %s", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,90 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Versão do protocolo falado entre servidor, cliente (cmd/client) e jogo.
+// A versão 1 é a anterior ao handshake: peers que não enviam versão (0 no gob)
+// são tratados como v1. A versão 3 troca o protocolo local de texto por
+// mensagens JSON (common/protocol); o RPC com o servidor não mudou.
+const (
+	ProtocolVersion    = 3
+	MinProtocolVersion = 1
+	// canais locais (jogo <-> client) só falam JSON a partir da v3
+	MinLocalProtocolVersion = 3
+)
+
+// ErrVersionMismatch é embrulhado pelos erros de CheckVersion e CheckLocalVersion.
+var ErrVersionMismatch = errors.New("protocol version mismatch")
+
+// Funcionalidades negociáveis. Um peer antigo recebe apenas a interseção.
+const (
+	FeatureMapLines = "map_lines" // GameState.MapLines / mapa no snapshot local
+	FeatureHello    = "hello"     // handshake Hello nos canais locais
+	FeaturePing     = "ping"      // GameServer.Ping e latência no snapshot local
+	FeatureResume   = "resume"    // RegisterArgs.ResumeID retoma o clientID após reconexão
+)
+
+// SupportedFeatures lista o que esta versão implementa.
+var SupportedFeatures = []string{FeatureMapLines, FeatureHello, FeaturePing, FeatureResume}
+
+// LegacyFeatures é o conjunto implícito de um peer v1.
+var LegacyFeatures = []string{FeatureMapLines}
+
+// EffectiveVersion converte a versão recebida (0 = campo ausente) em versão real.
+func EffectiveVersion(v int) int {
+	if v == 0 {
+		return 1
+	}
+	return v
+}
+
+// CheckVersion retorna um erro descritivo se a versão do peer não é suportada
+// no RPC com o servidor.
+func CheckVersion(peer int) error {
+	return checkVersion(peer, MinProtocolVersion)
+}
+
+// CheckLocalVersion é CheckVersion para os canais locais entre jogo e client.
+func CheckLocalVersion(peer int) error {
+	return checkVersion(peer, MinLocalProtocolVersion)
+}
+
+func checkVersion(peer, min int) error {
+	peer = EffectiveVersion(peer)
+	if peer < min || peer > ProtocolVersion {
+		return fmt.Errorf("%w: peer speaks v%d, this build supports v%d..v%d",
+			ErrVersionMismatch, peer, min, ProtocolVersion)
+	}
+	return nil
+}
+
+// NegotiateFeatures retorna as funcionalidades presentes nos dois lados, na ordem de SupportedFeatures.
+func NegotiateFeatures(peerVersion int, peer []string) []string {
+	if EffectiveVersion(peerVersion) == 1 && len(peer) == 0 {
+		peer = LegacyFeatures
+	}
+	set := make(map[string]bool, len(peer))
+	for _, f := range peer {
+		set[f] = true
+	}
+	out := []string{}
+	for _, f := range SupportedFeatures {
+		if set[f] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// HasFeature informa se f está em features.
+func HasFeature(features []string, f string) bool {
+	for _, x := range features {
+		if x == f {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,32 @@
 package main
 
-import "context"
+import (
+	"context"
+	"fmt"
+
+	"jogo/common/shared"
+)
 
 const InvisibilityDuration = 20
 
+// ContatoInvisivel define o que acontece quando um monstro encosta no
+// jogador invisível (flag --invisible-contact).
+type ContatoInvisivel string
+
+const (
+	ContatoIgnorar ContatoInvisivel = "ignore" // o monstro passa sem notar
+	ContatoRevelar ContatoInvisivel = "reveal" // a invisibilidade acaba e os monstros voltam a ver o jogador
+	ContatoPegar   ContatoInvisivel = "catch"  // pega o jogador como se estivesse visível
+)
+
+func contatoInvisivel(s string) (ContatoInvisivel, error) {
+	switch c := ContatoInvisivel(s); c {
+	case ContatoIgnorar, ContatoRevelar, ContatoPegar:
+		return c, nil
+	}
+	return "", fmt.Errorf("invalid invisible contact rule %q (use ignore, reveal or catch)", s)
+}
+
 // Eventos produzidos pelo elemento
 const (
 	EventApplyInvisibility = "ApplyInvisibility"
@@ -49,6 +72,7 @@ func (i *Invisibility) Run(ctx context.Context, out chan<- GameEvent, picked <-c
 func ConsumirItemInvisibilidade(jogo *Jogo) bool {
 	if jogo.UltimoVisitado.simbolo == InvisibilityItem.simbolo {
 		jogo.UltimoVisitado = Vazio
+		jogoAgendarReaparecimento(jogo, shared.ItemInvisibility, InvisibilityItem, jogo.PosX, jogo.PosY)
 		return true
 	}
 	return false
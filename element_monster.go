@@ -7,6 +7,31 @@ import (
 	"time"
 )
 
+func novoMonstro(x, y int, id string) *Monster {
+	return &Monster{
+		current_position: Position{X: x, Y: y},
+		state:            Patrolling,
+		destiny_position: Position{X: x + 5, Y: y + 5},
+		id:               id,
+		moves:            make(chan Position, 1),
+		player:           make(chan PlayerState, 10),
+		alerts:           make(chan PlayerAlert, 10),
+		drawn_position:   Position{X: x, Y: y},
+	}
+}
+
+// confirmarPosicao é chamado pelo loop do jogo quando aceita um movimento; a
+// goroutine do monstro só anda depois de receber a posição aceita.
+func (m *Monster) confirmarPosicao(p Position) {
+	m.drawn_facing = Position{X: p.X - m.drawn_position.X, Y: p.Y - m.drawn_position.Y}
+	m.drawn_position = p
+	select {
+	case <-m.moves: // descarta uma posição ainda não lida
+	default:
+	}
+	m.moves <- p
+}
+
 func (m *Monster) Run(ctx context.Context, out chan<- GameEvent, alerts <-chan PlayerAlert, pstate <-chan PlayerState) {
 	// Timer para controlar velocidade do monstro
 	ticker := time.NewTicker(30 * time.Millisecond)
@@ -21,7 +46,19 @@ func (m *Monster) Run(ctx context.Context, out chan<- GameEvent, alerts <-chan P
 		case <-ctx.Done():
 			return
 
+		case p := <-m.moves:
+			m.facing = Position{X: p.X - m.current_position.X, Y: p.Y - m.current_position.Y}
+			m.current_position = p
+			if len(m.caminho) > 0 && m.caminho[0] == p {
+				m.caminho = m.caminho[1:]
+			}
+
 		case playerState := <-pstate:
+			if playerState.Invisivel {
+				// a posição não conta: só um alerta de barulho denuncia o jogador
+				m.loseTrack()
+				continue
+			}
 			m.updatePlayerPosition(playerState)
 
 			// Reset do timeout quando recebe posição do jogador
@@ -90,27 +127,45 @@ func (m *Monster) shouldMove() bool {
 	return false
 }
 func (m *Monster) updatePlayerPosition(playerState PlayerState) {
-	playerPos := Position(playerState)
+	playerPos := Position{X: playerState.X, Y: playerState.Y}
 
-	// Calcula distância até o jogador
-	if m.canSeePlayer(playerPos) {
+	// Só a linha de visada revela a posição: fora dela o monstro vai até onde
+	// viu o jogador por último (last_seen fica parado)
+	m.seeing_player = m.canSeePlayer(playerPos)
+	if m.seeing_player {
 		m.state = Hunting
 		m.last_seen = playerPos
 		m.destiny_position = playerPos
 	} else if m.state == Hunting {
 		m.destiny_position = m.last_seen
-		if m.distanceTo(m.last_seen) < 0.5 {
-			m.state = Patrolling
-			m.generateAggressivePatrolDestiny()
-		}
+		m.giveUpAtLastSeen()
 	}
+}
 
+// giveUpAtLastSeen volta à patrulha quando o monstro chega onde viu o jogador
+// por último sem voltar a vê-lo.
+func (m *Monster) giveUpAtLastSeen() {
+	if m.state == Hunting && !m.seeing_player && m.distanceTo(m.last_seen) < 0.5 {
+		m.state = Patrolling
+		m.generateAggressivePatrolDestiny()
+	}
+}
+
+// loseTrack é chamado quando o jogador fica invisível: o monstro para de
+// caçar e patrulha perto de onde o viu por último.
+func (m *Monster) loseTrack() {
+	m.seeing_player = false
 	if m.state == Hunting {
-		m.destiny_position = playerPos
-		m.last_seen = playerPos
+		m.state = Patrolling
+		m.generateAggressivePatrolDestiny()
 	}
-} // Executa movimento baseado no estado atual
+}
+
+// Executa movimento baseado no estado atual
 func (m *Monster) processMovement(out chan<- GameEvent) {
+	// o jogador pode ficar parado fora de vista: desiste ao chegar em last_seen
+	m.giveUpAtLastSeen()
+
 	// Se está patrulhando e chegou no destino, gerar novo destino
 	if m.state == Patrolling && m.distanceTo(m.destiny_position) < 1 {
 		m.generateRandomDestiny()
@@ -118,6 +173,17 @@ func (m *Monster) processMovement(out chan<- GameEvent) {
 
 	oldX, oldY := m.current_position.X, m.current_position.Y
 	newPos := m.calculateNextPosition(m.destiny_position)
+	if newPos == m.current_position {
+		switch {
+		case m.state == Patrolling:
+			m.generateRandomDestiny() // destino inalcançável (parede, área fechada)
+		case !m.seeing_player:
+			// last_seen inalcançável: desiste da caçada
+			m.state = Patrolling
+			m.generateAggressivePatrolDestiny()
+		}
+		return
+	}
 
 	event := GameEvent{
 		Type: "monster_move",
@@ -137,10 +203,9 @@ func (m *Monster) processMovement(out chan<- GameEvent) {
 	}
 }
 
-// Verifica se pode ver o jogador
+// Verifica se pode ver o jogador (linha de visada, ver visao.go)
 func (m *Monster) canSeePlayer(playerPos Position) bool {
-	distance := m.distanceTo(playerPos)
-	return distance <= 25.0
+	return m.visao.enxerga(m.mapa, m.current_position, m.facing, playerPos)
 }
 
 // Calcula distância euclidiana entre monstro e uma posição
@@ -150,104 +215,82 @@ func (m *Monster) distanceTo(pos Position) float64 {
 	return math.Sqrt(dx*dx + dy*dy)
 }
 
+// Próximo passo no menor caminho até target, contornando paredes. O caminho
+// fica em cache enquanto o alvo não muda e o monstro está no início dele.
 func (m *Monster) calculateNextPosition(target Position) Position {
-	currentPos := m.current_position
-
-	// Calcula direção
-	dx := target.X - currentPos.X
-	dy := target.Y - currentPos.Y
-
-	newPos := currentPos
-
-	if m.state == Hunting {
-		// Prioriza movimento diagonal quando possível pois é mais rapido
-		if dx != 0 && dy != 0 {
-			if dx > 0 {
-				newPos.X++
-			} else {
-				newPos.X--
-			}
-			if dy > 0 {
-				newPos.Y++
-			} else {
-				newPos.Y--
-			}
-		} else if dx != 0 {
-			if dx > 0 {
-				newPos.X++
-			} else {
-				newPos.X--
-			}
-		} else if dy != 0 {
-			if dy > 0 {
-				newPos.Y++
-			} else {
-				newPos.Y--
-			}
-		}
-	} else {
-		if dx != 0 {
-			if dx > 0 {
-				newPos.X++
-			} else {
-				newPos.X--
-			}
-		} else if dy != 0 {
-			if dy > 0 {
-				newPos.Y++
-			} else {
-				newPos.Y--
-			}
+	if target == m.current_position {
+		return target
+	}
+	if target != m.caminho_alvo || len(m.caminho) == 0 || !vizinhas(m.current_position, m.caminho[0]) {
+		m.caminho_alvo = target
+		if m.state == Hunting {
+			// Caçando usa diagonais, pois é mais rapido
+			m.caminho = jogoCaminhoDiagonal(m.current_position, target, 0, m.mapa.livre)
+		} else {
+			m.caminho = jogoCaminho(m.current_position, target, 0, m.mapa.livre)
 		}
 	}
-
-	return newPos
+	if len(m.caminho) == 0 {
+		return m.current_position // sem caminho
+	}
+	return m.caminho[0]
 }
-func (m *Monster) generateRandomDestiny() {
-	radius := 10
-	maxTries := 10
-
-	for tries := 0; tries < maxTries; tries++ {
-		angle := rand.Float64() * 2 * math.Pi
-		distance := rand.Float64() * float64(radius)
-
-		newX := m.current_position.X + int(distance*math.Cos(angle))
-		newY := m.current_position.Y + int(distance*math.Sin(angle))
 
-		// Verificar se está dentro dos limites do mapa
-		if newX >= 1 && newX < 79 && newY >= 1 && newY < 29 {
-			m.destiny_position = Position{X: newX, Y: newY}
-			return
+// Patrulha: próximo ponto de patrulha do mapa, ou uma célula alcançável num
+// raio de 10 quando o mapa não define pontos.
+func (m *Monster) generateRandomDestiny() {
+	if n := len(m.waypoints); n > 0 {
+		i := rand.Intn(n)
+		if m.waypoints[i] == m.destiny_position && n > 1 {
+			i = (i + 1 + rand.Intn(n-1)) % n // outro ponto que não o atual
 		}
+		m.destiny_position = m.waypoints[i]
+		return
 	}
-
-	m.destiny_position = Position{
-		X: m.current_position.X + (rand.Intn(3) - 1), // -1, 0, ou 1
-		Y: m.current_position.Y + (rand.Intn(3) - 1), // -1, 0, ou 1
-	}
+	m.destiny_position = m.randomCellWithin(m.current_position, 10)
 }
 
+// Perdeu o jogador de vista: vai ao ponto de patrulha mais próximo de onde o
+// viu por último, ou a uma célula alcançável num raio de 15 dela.
 func (m *Monster) generateAggressivePatrolDestiny() {
-	radius := 15
-	maxTries := 10
-
-	for tries := 0; tries < maxTries; tries++ {
-		angle := rand.Float64() * 2 * math.Pi
-		distance := rand.Float64() * float64(radius)
-
-		newX := m.current_position.X + int(distance*math.Cos(angle))
-		newY := m.current_position.Y + int(distance*math.Sin(angle))
-
-		if newX >= 1 && newX < 79 && newY >= 1 && newY < 29 {
-			m.destiny_position = Position{X: newX, Y: newY}
-			return
+	if len(m.waypoints) > 0 {
+		best := m.waypoints[0]
+		for _, p := range m.waypoints[1:] {
+			if dist(p, m.last_seen) < dist(best, m.last_seen) {
+				best = p
+			}
 		}
+		m.destiny_position = best
+		return
 	}
+	m.destiny_position = m.randomCellWithin(m.last_seen, 15)
+}
 
-	m.destiny_position = Position{
-		X: m.current_position.X + (rand.Intn(5) - 2), // -2 a 2
-		Y: m.current_position.Y + (rand.Intn(5) - 2), // -2 a 2
+// randomCellWithin sorteia uma célula da área do monstro a até radius de
+// center; sem nenhuma, qualquer célula da área (ou fica parado).
+func (m *Monster) randomCellWithin(center Position, radius float64) Position {
+	var near, all []Position
+	for p := range m.area {
+		if p == m.current_position {
+			continue
+		}
+		all = append(all, p)
+		if dist(p, center) <= radius {
+			near = append(near, p)
+		}
 	}
+	switch {
+	case len(near) > 0:
+		return near[rand.Intn(len(near))]
+	case len(all) > 0:
+		return all[rand.Intn(len(all))]
+	}
+	return m.current_position
+}
+
+func dist(a, b Position) float64 {
+	dx, dy := float64(a.X-b.X), float64(a.Y-b.Y)
+	return math.Sqrt(dx*dx + dy*dy)
 }
 
 // Processa alertas recebidos
@@ -128,7 +128,7 @@ func (s *Star) Run(ctx context.Context, gameEvents chan<- GameEvent, playerState
 			return
 
 		case playerPos := <-playerState:
-			s.LastPlayerPos = Position(playerPos)
+			s.LastPlayerPos = Position{X: playerPos.X, Y: playerPos.Y}
 			s.handlePlayerMovement(gameEvents, playerPos)
 
 		case collect := <-playerCollects:
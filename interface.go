@@ -1,6 +1,10 @@
 package main
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/nsf/termbox-go"
 )
 
@@ -20,6 +24,7 @@ const (
 	CorFundoParede     = termbox.ColorDarkGray
 	CorTexto           = termbox.ColorDarkGray
 	CorAmarelo         = termbox.ColorYellow
+	CorFundoVisao      = termbox.ColorBlue
 )
 
 // EventoTeclado representa uma ação detectada do teclado (como mover, sair ou interagir)
@@ -66,22 +71,35 @@ func interfaceDesenharJogo(jogo *Jogo) {
 		}
 	}
 
+	if jogo.MostrarVisao {
+		interfaceDesenharVisao(jogo)
+	}
+
 	// Desenha o personagem sobre o mapa
 	interfaceDesenharElemento(jogo.PosX, jogo.PosY, jogo.elementoJogador())
 
 	// Desenha outros jogadores recebidos do servidor
 	outro := Elemento{'☺', CorCiano, CorPadrao, true}
+	desatualizado := Elemento{'☺', CorCinzaEscuro, CorPadrao, true}
+	agora := time.Now()
+	velhos := 0
 	for id, rp := range jogo.RemotePlayers {
 		if id == jogo.SelfID {
 			// não desenha a si mesmo (já desenhado localmente)
 			continue
 		}
-		interfaceDesenharElemento(rp.X, rp.Y, outro)
+		x, y, velho := jogoPosicaoRemota(jogo, rp, agora)
+		if velho {
+			velhos++
+			interfaceDesenharElemento(x, y, desatualizado)
+		} else {
+			interfaceDesenharElemento(x, y, outro)
+		}
 	}
 
-	// Desenha o monstro se existir
-	if jogo.Monstro != nil {
-		interfaceDesenharElemento(jogo.Monstro.current_position.X, jogo.Monstro.current_position.Y, Inimigo)
+	// Desenha os monstros
+	for _, m := range jogo.Monstros {
+		interfaceDesenharElemento(m.drawn_position.X, m.drawn_position.Y, Inimigo)
 	}
 
 	// Desenha as estrelas
@@ -91,10 +109,27 @@ func interfaceDesenharJogo(jogo *Jogo) {
 			interfaceDesenharElemento(star.X, star.Y, starElement)
 		}
 	}
-	interfaceDesenharBarraDeStatus(jogo)
+	interfaceDesenharBarraDeStatus(jogo, velhos)
 	interfaceAtualizarTela()
 }
 
+// Pinta o fundo das células que algum monstro enxerga agora (depuração)
+func interfaceDesenharVisao(jogo *Jogo) {
+	for _, m := range jogo.Monstros {
+		r := int(m.visao.Alcance)
+		for y := m.drawn_position.Y - r; y <= m.drawn_position.Y+r; y++ {
+			for x := m.drawn_position.X - r; x <= m.drawn_position.X+r; x++ {
+				if m.mapa.opaca(x, y) || !m.visao.enxerga(m.mapa, m.drawn_position, m.drawn_facing, Position{x, y}) {
+					continue
+				}
+				e := jogo.Mapa[y][x]
+				e.corFundo = CorFundoVisao
+				interfaceDesenharElemento(x, y, e)
+			}
+		}
+	}
+}
+
 func interfaceLimparTela() {
 	termbox.Clear(CorPadrao, CorPadrao)
 }
@@ -107,14 +142,36 @@ func interfaceDesenharElemento(x, y int, elem Elemento) {
 	termbox.SetCell(x, y, elem.simbolo, elem.cor, elem.corFundo)
 }
 
-func interfaceDesenharBarraDeStatus(jogo *Jogo) {
+// velhos é o número de outros jogadores desenhados como desatualizados.
+func interfaceDesenharBarraDeStatus(jogo *Jogo, velhos int) {
 
 	for i, c := range jogo.StatusMsg {
 		termbox.SetCell(i, len(jogo.Mapa)+1, c, CorTexto, CorPadrao)
 	}
 
+	// Latência e estado da conexão com o servidor (quando jogando online)
+	rede := ""
+	if jogo.PingConhecido {
+		rede = fmt.Sprintf("Ping: %d ms", jogo.Ping.Milliseconds())
+	}
+	// fila no client + movimentos ainda não escritos na sessão local
+	if fila := jogo.FilaComandos + jogo.Sessao.pendentes(); fila > 1 {
+		rede = strings.TrimSpace(fmt.Sprintf("%s  Fila: %d", rede, fila))
+	}
+	if velhos > 0 {
+		rede = strings.TrimSpace(fmt.Sprintf("%s  Sem atualização: %d jogador(es)", rede, velhos))
+	}
+	corRede := CorTexto
+	if jogo.ConexaoMsg != "" {
+		rede = strings.TrimSpace(rede + "  Servidor: " + jogo.ConexaoMsg)
+		corRede = CorVermelho
+	}
+	for i, c := range []rune(rede) {
+		termbox.SetCell(i, len(jogo.Mapa)+2, c, corRede, CorPadrao)
+	}
+
 	// Instruções fixas
-	msg := "Use WASD para mover e E para interagir. ESC para sair."
+	msg := "Use WASD para mover, E para interagir e V para ver a visão dos monstros. ESC para sair."
 	for i, c := range msg {
 		termbox.SetCell(i, len(jogo.Mapa)+3, c, CorTexto, CorPadrao)
 	}
@@ -138,6 +195,8 @@ func interfaceLerEventoTecladoAsync() <-chan EventoTeclado {
 					switch ev.Ch {
 					case 'e', 'E':
 						evento.Tipo = "interagir"
+					case 'v', 'V':
+						evento.Tipo = "visao"
 					case 'w', 'W', 'a', 'A', 's', 'S', 'd', 'D':
 						evento.Tipo = "mover"
 						evento.Tecla = ev.Ch
@@ -0,0 +1,99 @@
+// interpolacao.go - movimento suave dos outros jogadores entre snapshots
+package main
+
+import "time"
+
+const (
+	// Os outros jogadores são desenhados com este atraso, um pouco maior que o
+	// intervalo de polling do client, para haver sempre dois snapshots em volta.
+	interpAtraso = 600 * time.Millisecond
+	// Com snapshots atrasados, continua o último movimento por até este tempo.
+	interpExtrapolarMax = 400 * time.Millisecond
+	// Jogador sem contato com o servidor (ou sem snapshot novo) por mais que
+	// isso é marcado como desatualizado.
+	interpVelho = 3 * time.Second
+	// Snapshots guardados por jogador
+	interpAmostras = 5
+	// Distância acima da qual o jogador salta (troca de mapa, respawn)
+	interpSaltoMax = 20
+)
+
+// AmostraRemota é a posição de um jogador num snapshot, com o caminho
+// percorrido desde a amostra anterior.
+type AmostraRemota struct {
+	T       time.Time // horário do snapshot no relógio local
+	X, Y    int
+	Caminho []Position // células desde a amostra anterior (exclusive) até X,Y
+}
+
+// jogoNovaAmostra acrescenta um snapshot ao histórico de rp. Não altera o
+// histórico antigo, que pode estar sendo desenhado ao mesmo tempo.
+func jogoNovaAmostra(jogo *Jogo, rp RemotePlayer, t time.Time) RemotePlayer {
+	amostra := AmostraRemota{T: t, X: rp.X, Y: rp.Y}
+	antigas := rp.Amostras
+	if n := len(antigas); n > 0 {
+		ult := antigas[n-1]
+		if !t.After(ult.T) {
+			amostra.T = ult.T.Add(time.Millisecond) // relógio estimado recuou
+		}
+		de, para := Position{ult.X, ult.Y}, Position{rp.X, rp.Y}
+		if dist := abs(para.X-de.X) + abs(para.Y-de.Y); dist <= interpSaltoMax {
+			amostra.Caminho = jogoCaminho(de, para, 4*interpSaltoMax*interpSaltoMax,
+				func(x, y int) bool { return jogoSemParede(jogo, x, y) })
+		}
+		if amostra.Caminho == nil {
+			amostra.Caminho = []Position{para} // sem caminho conhecido: salta
+		}
+	}
+	if len(antigas) >= interpAmostras {
+		antigas = antigas[len(antigas)-interpAmostras+1:]
+	}
+	rp.Amostras = append(append([]AmostraRemota(nil), antigas...), amostra)
+	return rp
+}
+
+// jogoPosicaoRemota calcula onde desenhar rp agora. velho indica que rp não
+// fala com o servidor há mais de interpVelho (ver RemotePlayer.Visto).
+func jogoPosicaoRemota(jogo *Jogo, rp RemotePlayer, agora time.Time) (x, y int, velho bool) {
+	velho = !rp.Visto.IsZero() && agora.Sub(rp.Visto) > interpVelho
+	a := rp.Amostras
+	if len(a) == 0 {
+		return rp.X, rp.Y, velho
+	}
+	ult := a[len(a)-1]
+	r := agora.Add(-interpAtraso)
+
+	if !r.After(a[0].T) {
+		return a[0].X, a[0].Y, velho
+	}
+	// interpola entre as duas amostras em volta de r
+	for i := 1; i < len(a); i++ {
+		if r.Before(a[i].T) {
+			frac := float64(r.Sub(a[i-1].T)) / float64(a[i].T.Sub(a[i-1].T))
+			k := int(frac * float64(len(a[i].Caminho)))
+			if k == 0 {
+				return a[i-1].X, a[i-1].Y, velho
+			}
+			p := a[i].Caminho[k-1]
+			return p.X, p.Y, velho
+		}
+	}
+	// snapshot atrasado: segue na mesma direção e velocidade por pouco tempo
+	atraso := r.Sub(ult.T)
+	if len(a) < 2 || atraso > interpExtrapolarMax || len(ult.Caminho) == 0 {
+		return ult.X, ult.Y, velho
+	}
+	ant := a[len(a)-2]
+	velocidade := float64(len(ult.Caminho)) / ult.T.Sub(ant.T).Seconds() // células/s
+	passos := int(velocidade * atraso.Seconds())
+	de := Position{ant.X, ant.Y}
+	if n := len(ult.Caminho); n > 1 {
+		de = ult.Caminho[n-2]
+	}
+	d := Position{ult.X - de.X, ult.Y - de.Y}
+	x, y = ult.X, ult.Y
+	for ; passos > 0 && jogoSemParede(jogo, x+d.X, y+d.Y); passos-- {
+		x, y = x+d.X, y+d.Y
+	}
+	return x, y, velho
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
-	"net"
 	"os"
 	"time"
+
+	"jogo/common/protocol"
 )
 
 // Elemento representa qualquer objeto do mapa (parede, personagem, vegetação, etc)
@@ -23,17 +25,34 @@ type Jogo struct {
 	StatusMsg         string
 	InvisibleSteps    int
 	DoubleJumps       int
-	Monstro           *Monster
+	Monstros          []*Monster
+	PontosPatrulha    []Position       // marcados com PontoPatrulha no mapa
+	ContatoInvisivel  ContatoInvisivel // monstro encostando no jogador invisível
+	VisaoMonstros     []Visao          // por monstro, na ordem do mapa (ver visaoDoMonstro)
+	MostrarVisao      bool             // sobrepõe o que cada monstro enxerga (tecla V)
 	InvisibilityItems []*Invisibility
 	Stars             []*Star
 	GameEvents        chan GameEvent
-	PlayerState       chan PlayerState
-	PlayerAlerts      chan PlayerAlert
 	PlayerCollects    chan PlayerCollect
 	StarCommands      chan StarCommand
 	MapMutex          chan chan bool
-	RemotePlayers     map[string]RemotePlayer // outros jogadores
-	SelfID            string                  // id do jogador local (para não duplicar)
+	RemotePlayers     map[string]RemotePlayer  // outros jogadores
+	SelfID            string                   // id do jogador local (para não duplicar)
+	Ping              time.Duration            // RTT até o servidor (informado pelo client)
+	ClockOffset       time.Duration            // relógio do servidor - relógio local
+	PingConhecido     bool                     // false até a primeira medição
+	ConexaoMsg        string                   // estado da conexão com o servidor ("" = online)
+	FilaComandos      int                      // comandos aguardando envio no client
+	ServidorEventos   chan GameEvent           // confirmações vindas do client (drenadas a cada tick)
+	Pendentes         []MovimentoPendente      // movimentos previstos ainda não confirmados
+	ProximaSeq        uint64                   // última sequência de movimento enviada
+	BaseX, BaseY      int                      // posição confirmada antes do primeiro pendente
+	Sessao            *sessaoCliente           // movimentos a enviar ao client (ver sessao.go)
+	MapaServidor      string                   // último mapa recebido do servidor ("" = mapa local)
+	TempoReaparecer   map[string]time.Duration // por item, informado pelo servidor (nil = não reaparecem)
+	Reaparecimentos   []ItemReaparecendo       // itens coletados esperando para voltar (ver reaparecer.go)
+	Contexto          context.Context          // encerra as goroutines dos elementos
+	pararMonstros     context.CancelFunc       // encerra as goroutines dos monstros atuais
 }
 
 // Elementos visuais do jogo
@@ -51,19 +70,73 @@ var (
 	StarElementCharging  = Elemento{'◉', CorVermelho, CorPadrao, false}
 )
 
-// Canal global para integração com o client.go
-var PosUpdateChan chan [2]int
+// Ponto de patrulha dos monstros no arquivo do mapa (desenhado como vazio)
+const PontoPatrulha = '◇'
 
+// O estado do jogo tem um único dono: o loop principal (main.go). Monstros,
+// estrelas e a sessão com o client rodam em goroutines próprias e só o
+// alteram por eventos (GameEvents, ServidorEventos), tratados a cada tick.
 func jogoNovo() Jogo {
 	return Jogo{
-		UltimoVisitado: Vazio,
-		GameEvents:     make(chan GameEvent, 10),
-		PlayerState:    make(chan PlayerState, 10),
-		PlayerAlerts:   make(chan PlayerAlert, 10),
-		PlayerCollects: make(chan PlayerCollect, 10),
-		StarCommands:   make(chan StarCommand, 10),
-		MapMutex:       make(chan chan bool, 1),
-		RemotePlayers:  make(map[string]RemotePlayer),
+		UltimoVisitado:   Vazio,
+		GameEvents:       make(chan GameEvent, 32),
+		PlayerCollects:   make(chan PlayerCollect, 10),
+		StarCommands:     make(chan StarCommand, 10),
+		MapMutex:         make(chan chan bool, 1),
+		RemotePlayers:    make(map[string]RemotePlayer),
+		ServidorEventos:  make(chan GameEvent, 64),
+		Sessao:           novaSessaoCliente(),
+		Contexto:         context.Background(),
+		ContatoInvisivel: ContatoIgnorar,
+	}
+}
+
+// jogoIniciarMonstros (re)inicia uma goroutine por monstro do mapa atual,
+// encerrando as do mapa anterior.
+func jogoIniciarMonstros(jogo *Jogo) {
+	if jogo.pararMonstros != nil {
+		jogo.pararMonstros()
+		jogo.pararMonstros = nil
+	}
+	if len(jogo.Monstros) == 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(jogo.Contexto)
+	jogo.pararMonstros = cancel
+	mapa := jogoMapaLeitura(jogo)
+	for i, m := range jogo.Monstros {
+		m.mapa = mapa
+		m.visao = visaoDoMonstro(jogo.VisaoMonstros, i)
+		// patrulha só dentro da área que o monstro alcança a partir do spawn
+		m.area = mapa.alcancaveis(m.current_position)
+		m.waypoints = nil
+		for _, p := range jogo.PontosPatrulha {
+			if m.area[p] {
+				m.waypoints = append(m.waypoints, p)
+			}
+		}
+		go m.Run(ctx, jogo.GameEvents, m.alerts, m.player)
+	}
+}
+
+// jogoMonstro procura um monstro pelo id (eventos vêm identificados por MonsterID).
+func jogoMonstro(jogo *Jogo, id string) *Monster {
+	for _, m := range jogo.Monstros {
+		if m.id == id {
+			return m
+		}
+	}
+	return nil
+}
+
+// jogoNotificarMonstros entrega a cada monstro sua cópia da posição do
+// jogador; monstro ocupado perde a atualização em vez de travar o jogo.
+func jogoNotificarMonstros(jogo *Jogo, ps PlayerState) {
+	for _, m := range jogo.Monstros {
+		select {
+		case m.player <- ps:
+		default:
+		}
 	}
 }
 
@@ -80,23 +153,18 @@ func jogoCarregarMapa(nome string, jogo *Jogo) error {
 	for scanner.Scan() {
 		linha := scanner.Text()
 		var linhaElems []Elemento
-		for x, ch := range linha {
+		for x, ch := range []rune(linha) { // x em células, não em bytes
 			e := Vazio
 			switch ch {
 			case Parede.simbolo:
 				e = Parede
 			case Inimigo.simbolo:
 				e = Vazio
-				if jogo.Monstro == nil {
-					jogo.Monstro = &Monster{
-						current_position: Position{X: x, Y: y},
-						state:            Patrolling,
-						destiny_position: Position{X: x + 5, Y: y + 5},
-						id:               "monster_1",
-					}
-				}
+				jogo.Monstros = append(jogo.Monstros, novoMonstro(x, y, fmt.Sprintf("monster_%d", len(jogo.Monstros)+1)))
 			case Vegetacao.simbolo:
 				e = Vegetacao
+			case PontoPatrulha:
+				jogo.PontosPatrulha = append(jogo.PontosPatrulha, Position{X: x, Y: y})
 			case InvisibilityItem.simbolo:
 				e = InvisibilityItem
 				invisItem := &Invisibility{X: x, Y: y}
@@ -120,28 +188,25 @@ func jogoCarregarMapa(nome string, jogo *Jogo) error {
 // Constrói o mapa a partir de linhas de texto fornecidas pelo servidor
 func jogoCarregarMapaDeLinhas(linhas []string, jogo *Jogo) error {
 	jogo.Mapa = nil
-	jogo.Monstro = nil
+	jogo.Monstros = nil
+	jogo.PontosPatrulha = nil
 	jogo.InvisibilityItems = nil
+	jogo.Reaparecimentos = nil
 	y := 0
 	for _, linha := range linhas {
 		var linhaElems []Elemento
-		for x, ch := range linha {
+		for x, ch := range []rune(linha) { // x em células, não em bytes
 			e := Vazio
 			switch ch {
 			case Parede.simbolo:
 				e = Parede
 			case Inimigo.simbolo:
 				e = Vazio
-				if jogo.Monstro == nil {
-					jogo.Monstro = &Monster{
-						current_position: Position{X: x, Y: y},
-						state:            Patrolling,
-						destiny_position: Position{X: x + 5, Y: y + 5},
-						id:               "monster_1",
-					}
-				}
+				jogo.Monstros = append(jogo.Monstros, novoMonstro(x, y, fmt.Sprintf("monster_%d", len(jogo.Monstros)+1)))
 			case Vegetacao.simbolo:
 				e = Vegetacao
+			case PontoPatrulha:
+				jogo.PontosPatrulha = append(jogo.PontosPatrulha, Position{X: x, Y: y})
 			case InvisibilityItem.simbolo:
 				e = InvisibilityItem
 				invisItem := &Invisibility{X: x, Y: y}
@@ -161,6 +226,20 @@ func jogoCarregarMapaDeLinhas(linhas []string, jogo *Jogo) error {
 	return nil
 }
 
+// Procura o símbolo do personagem (posição inicial) nas linhas de um mapa
+func jogoPosicaoInicial(linhas []string) (int, int, bool) {
+	for y, linha := range linhas {
+		x := 0
+		for _, ch := range linha {
+			if ch == Personagem.simbolo {
+				return x, y, true
+			}
+			x++
+		}
+	}
+	return 0, 0, false
+}
+
 // Verifica se o personagem pode se mover para a posição (x, y)
 func jogoPodeMoverPara(jogo *Jogo, x, y int) bool {
 	if y < 0 || y >= len(jogo.Mapa) {
@@ -185,9 +264,9 @@ func jogoMoverElemento(jogo *Jogo, x, y, dx, dy int) {
 	jogo.Mapa[ny][nx] = elemento
 	jogo.PosX, jogo.PosY = nx, ny
 
-	// Envia posição para o cliente local e canal global
-	jogoEnviarEstadoJogador(jogo)
-	jogo.ReportarMovimento()
+	// Guarda a predição e envia a posição ao client pela sessão local
+	seq := jogoRegistrarMovimento(jogo, dx, dy)
+	jogoEnviarEstadoJogador(jogo, seq)
 }
 
 func (j *Jogo) elementoJogador() Elemento {
@@ -198,10 +277,19 @@ func (j *Jogo) elementoJogador() Elemento {
 }
 
 func jogoProcessarEventos(jogo *Jogo) {
-	select {
-	case event := <-jogo.GameEvents:
-		jogoTratarEvento(jogo, event)
-	default:
+	jogoReaparecerItens(jogo, time.Now())
+	// um evento por elemento a cada tick, em média: trata os que já chegaram
+	for n := len(jogo.GameEvents); n > 0; n-- {
+		jogoTratarEvento(jogo, <-jogo.GameEvents)
+	}
+	// eventos da sessão (estado, confirmações) são poucos e não podem se perder: drena todos
+	for {
+		select {
+		case event := <-jogo.ServidorEventos:
+			jogoTratarEvento(jogo, event)
+		default:
+			return
+		}
 	}
 }
 
@@ -210,8 +298,10 @@ func jogoTratarEvento(jogo *Jogo, event GameEvent) {
 	case "monster_move":
 		if data, ok := event.Data.(MonsterMoveData); ok {
 			if jogoPodeMoverPara(jogo, data.NewX, data.NewY) {
-				if jogo.Monstro != nil && jogo.Monstro.id == data.MonsterID {
-					jogo.Monstro.current_position = Position{X: data.NewX, Y: data.NewY}
+				// OldX/OldY diferente: evento de um monstro já substituído (troca de mapa)
+				m := jogoMonstro(jogo, data.MonsterID)
+				if m != nil && m.drawn_position == (Position{X: data.OldX, Y: data.OldY}) {
+					m.confirmarPosicao(Position{X: data.NewX, Y: data.NewY})
 					if data.NewX == jogo.PosX && data.NewY == jogo.PosY {
 						collisionEvent := GameEvent{
 							Type: "monster_collision",
@@ -225,7 +315,34 @@ func jogoTratarEvento(jogo *Jogo, event GameEvent) {
 				}
 			}
 		}
+	case EventConfirmacaoServidor:
+		if data, ok := event.Data.(ConfirmacaoServidor); ok {
+			jogoReconciliar(jogo, data)
+		}
+	case EventEstadoServidor:
+		if st, ok := event.Data.(*protocol.State); ok {
+			aplicarEstado(jogo, st)
+		}
+	case EventStatusServidor:
+		if msg, ok := event.Data.(string); ok {
+			jogo.ConexaoMsg = msg
+		}
+	case EventMensagemStatus:
+		if msg, ok := event.Data.(string); ok {
+			jogo.StatusMsg = msg
+		}
 	case "monster_collision":
+		if jogo.InvisibleSteps > 0 {
+			switch jogo.ContatoInvisivel {
+			case ContatoIgnorar:
+				return
+			case ContatoRevelar:
+				jogo.InvisibleSteps = 0
+				jogo.StatusMsg = "Um monstro esbarrou em você: invisibilidade perdida!"
+				jogoNotificarMonstros(jogo, PlayerState{X: jogo.PosX, Y: jogo.PosY})
+				return
+			}
+		}
 		jogo.StatusMsg = "Pego pelo monstro!"
 	case EventApplyInvisibility:
 		if data, ok := event.Data.(InvisibilityApplied); ok {
@@ -240,34 +357,9 @@ func jogoTratarEvento(jogo *Jogo, event GameEvent) {
 	}
 }
 
-// Notifica o client.go via TCP
-func jogoEnviarEstadoJogador(jogo *Jogo) {
-	go func(x, y int) {
-		addr := os.Getenv("GAME_CMD_ADDR")
-		if addr == "" {
-			addr = "127.0.0.1:4000"
-		}
-		conn, err := net.DialTimeout("tcp", addr, 200*time.Millisecond)
-		if err != nil {
-			return
-		}
-		defer conn.Close()
-		// Envia comando
-		fmt.Fprintf(conn, "MOVE %d %d\n", x, y)
-
-		// Handshake: tenta ler uma resposta rápida antes de fechar (timeout curto)
-		_ = conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
-		reader := bufio.NewReader(conn)
-		_, _ = reader.ReadString('\n')
-	}(jogo.PosX, jogo.PosY)
-}
-
-// Envia posição pro canal PosUpdateChan (usado pelo client.go)
-func (j *Jogo) ReportarMovimento() {
-	if PosUpdateChan != nil {
-		select {
-		case PosUpdateChan <- [2]int{j.PosX, j.PosY}:
-		default:
-		}
+// Notifica o client.go pela sessão local (seq identifica o movimento nas confirmações)
+func jogoEnviarEstadoJogador(jogo *Jogo, seq uint64) {
+	if s := jogo.Sessao.enviarMovimento(protocol.Move{X: jogo.PosX, Y: jogo.PosY, Seq: seq}); s != 0 {
+		jogoDescartarPendente(jogo, s) // nunca será confirmado
 	}
 }
@@ -1,34 +1,76 @@
 package main
 
 import (
-	"bufio"
 	"context"
-	"net"
+	"flag"
+	"io"
+	"log"
 	"os"
-	"strconv"
-	"strings"
 	"time"
+
+	"jogo/common/client"
+	"jogo/common/shared"
 )
 
 func main() {
+	serverAddr := flag.String("server", "", "server address (ip:port) to play online with the client embedded; empty = use a separate cmd/client (GAME_STATE_ADDR)")
+	name := flag.String("name", "Player", "player name (with --server)")
+	tlsCA := flag.String("tls-ca", "", "CA file used to verify the server certificate (with --server)")
+	tlsPin := flag.String("tls-pin", "", "expected SHA-256 fingerprint of the server certificate (with --server)")
+	monsterVision := flag.String("monster-vision", "25/360", "monster sight as range/cone-degrees, one per monster in map order, comma-separated; the last applies to the rest")
+	invisibleContact := flag.String("invisible-contact", string(ContatoIgnorar), "when a monster touches an invisible player: ignore, reveal (invisibility ends) or catch")
+	flag.Parse()
+
+	contato, err := contatoInvisivel(*invisibleContact)
+	if err != nil {
+		log.Fatal(err)
+	}
+	visoes, err := parseVisoes(*monsterVision)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Client embutido: conecta antes de abrir a tela para erros aparecerem no terminal
+	var embutido *client.Client
+	if *serverAddr != "" {
+		tlsCfg, err := shared.ClientTLSConfig(shared.TLSOptions{CAFile: *tlsCA, PinSHA256: *tlsPin})
+		if err != nil {
+			log.Fatalf("Invalid TLS configuration: %v", err)
+		}
+		client.SetLogOutput(io.Discard) // logs do client estragariam a tela do termbox
+		embutido, err = client.NewClient(*name, *serverAddr, tlsCfg)
+		if err != nil {
+			log.Fatalf("Failed to connect/register: %v", err)
+		}
+		embutido.StartPolling()
+		embutido.StartPinger(2 * time.Second)
+	}
+
 	// Initialize UI and run the local game (independent process)
 	interfaceIniciar()
 	defer interfaceFinalizar()
 
+	// Inicia elementos concorrentes (monstro, etc.)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// Cria novo jogo
 	jogo := jogoNovo()
+	jogo.Contexto = ctx
+	jogo.ContatoInvisivel = contato
+	jogo.VisaoMonstros = visoes
 	_ = jogoCarregarMapa("mapa.txt", &jogo) // mapa local inicial
 
-	// Inicia sincronização com estado do client local (se disponível)
-	addr := os.Getenv("GAME_STATE_ADDR")
-	if addr == "" {
-		addr = "127.0.0.1:4001"
+	if embutido != nil {
+		go startSessaoEmbutida(&jogo, embutido)
+	} else {
+		// Inicia sincronização com estado do client local (se disponível)
+		addr := os.Getenv("GAME_STATE_ADDR")
+		if addr == "" {
+			addr = "127.0.0.1:4001"
+		}
+		go startStateSync(&jogo, addr)
 	}
-	go startStateSync(&jogo, addr)
-
-	// Inicia elementos concorrentes (monstro, etc.)
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
 
 	// Mutex de mapa (para elementos que pedem acesso exclusivo)
 	go func() {
@@ -42,9 +84,7 @@ func main() {
 		}
 	}()
 
-	if jogo.Monstro != nil {
-		go jogo.Monstro.Run(ctx, jogo.GameEvents, jogo.PlayerAlerts, jogo.PlayerState)
-	}
+	jogoIniciarMonstros(&jogo)
 
 	// Loop principal do jogo (não-bloqueante para processar eventos)
 	evCh := interfaceLerEventoTecladoAsync()
@@ -57,7 +97,7 @@ func main() {
 			if ev.Tipo == "sair" {
 				return
 			}
-			_ = personagemExecutarAcaoComCanal(ev, &jogo, jogo.PlayerState)
+			_ = personagemExecutarAcaoComCanal(ev, &jogo)
 		case <-ticker.C:
 			// processa eventos do jogo e redesenha periodicamente
 			jogoProcessarEventos(&jogo)
@@ -65,67 +105,3 @@ func main() {
 		}
 	}
 }
-
-// Conecta ao broadcaster local do client (127.0.0.1:4001) e atualiza mapa/jogadores
-func startStateSync(j *Jogo, addr string) {
-	appliedMap := false
-	for {
-		conn, err := net.Dial("tcp", addr)
-		if err != nil {
-			time.Sleep(500 * time.Millisecond)
-			continue
-		}
-		rd := bufio.NewScanner(conn)
-		rd.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-		var mapLines []string
-		for rd.Scan() {
-			line := strings.TrimSpace(rd.Text())
-			if line == "" {
-				continue
-			}
-			if strings.HasPrefix(line, "SELF ") {
-				j.SelfID = strings.TrimSpace(strings.TrimPrefix(line, "SELF "))
-			} else if strings.HasPrefix(line, "MAP ") {
-				// read N lines
-				parts := strings.Fields(line)
-				if len(parts) >= 2 {
-					if n, err := strconv.Atoi(parts[1]); err == nil && n > 0 {
-						mapLines = make([]string, 0, n)
-						for i := 0; i < n && rd.Scan(); i++ {
-							mapLines = append(mapLines, rd.Text())
-						}
-						// aplica mapa apenas uma vez ao conectar
-						if len(mapLines) > 0 && !appliedMap {
-							_ = jogoCarregarMapaDeLinhas(mapLines, j)
-							appliedMap = true
-						}
-					}
-				}
-			} else if strings.HasPrefix(line, "PLAYERS ") {
-				parts := strings.Fields(line)
-				count := 0
-				if len(parts) >= 2 {
-					if n, err := strconv.Atoi(parts[1]); err == nil {
-						count = n
-					}
-				}
-				// zera players anteriores, vamos repovoar
-				j.RemotePlayers = make(map[string]RemotePlayer, count)
-				for i := 0; i < count && rd.Scan(); i++ {
-					pl := strings.Split(rd.Text(), "\t")
-					if len(pl) < 4 {
-						continue
-					}
-					x, _ := strconv.Atoi(pl[2])
-					y, _ := strconv.Atoi(pl[3])
-					j.RemotePlayers[pl[0]] = RemotePlayer{ID: pl[0], Name: pl[1], X: x, Y: y}
-				}
-			} else if line == "END" {
-				// snapshot completo recebido
-			}
-		}
-		conn.Close()
-		// reconectar em caso de fechamento
-		time.Sleep(300 * time.Millisecond)
-	}
-}
@@ -4,6 +4,8 @@ package main
 import (
 	"fmt"
 	"math/rand"
+
+	"jogo/common/shared"
 )
 
 // Atualiza a posição do personagem com base na tecla pressionada (WASD)
@@ -120,9 +122,11 @@ func jogoEnviarAlerta(jogo *Jogo, tipoAlerta string) {
 		},
 	}
 
-	select {
-	case jogo.PlayerAlerts <- alert:
-	default:
+	for _, m := range jogo.Monstros {
+		select {
+		case m.alerts <- alert:
+		default:
+		}
 	}
 }
 
@@ -137,11 +141,9 @@ func personagemExecutarAcao(ev EventoTeclado, jogo *Jogo) bool {
 		personagemInteragir(jogo)
 
 	case "mover":
+		// personagemMover já notifica o cliente local quando o movimento acontece
 		personagemMover(ev.Tecla, jogo)
 
-		// Envia posição para o cliente local (127.0.0.1:4000)
-		jogoEnviarEstadoJogador(jogo)
-
 		// Envia evento de barulho para o monstro (20% de chance)
 		if rand.Float32() < 0.2 {
 			jogoEnviarAlerta(jogo, "noise")
@@ -150,8 +152,8 @@ func personagemExecutarAcao(ev EventoTeclado, jogo *Jogo) bool {
 	return true
 }
 
-// Versão com canal (usada no loop principal do jogo)
-func personagemExecutarAcaoComCanal(ev EventoTeclado, jogo *Jogo, playerChannel chan<- PlayerState) bool {
+// Versão com canais (usada no loop principal do jogo): cada monstro recebe a posição
+func personagemExecutarAcaoComCanal(ev EventoTeclado, jogo *Jogo) bool {
 	switch ev.Tipo {
 	case "sair":
 		return false
@@ -159,21 +161,20 @@ func personagemExecutarAcaoComCanal(ev EventoTeclado, jogo *Jogo, playerChannel
 	case "interagir":
 		personagemInteragir(jogo)
 
+	case "visao":
+		jogo.MostrarVisao = !jogo.MostrarVisao
+
 	case "mover":
 		personagemMover(ev.Tecla, jogo)
 
-		// Envia estado pro canal interno (mantém compatibilidade)
-		playerState := PlayerState{
-			X: jogo.PosX,
-			Y: jogo.PosY,
-		}
-		select {
-		case playerChannel <- playerState:
-		default:
-		}
+		// Envia estado para os monstros
+		jogoNotificarMonstros(jogo, PlayerState{
+			X:         jogo.PosX,
+			Y:         jogo.PosY,
+			Invisivel: jogo.InvisibleSteps > 0,
+		})
 
-		// *** ADIÇÃO: notificar o cliente local via TCP ***
-		jogoEnviarEstadoJogador(jogo)
+		// o cliente local já foi notificado em jogoMoverElemento (com a sequência do movimento)
 
 		// *** OPCIONAL: chance de barulho ***
 		if rand.Float32() < 0.2 {
@@ -188,6 +189,7 @@ func personagemExecutarAcaoComCanal(ev EventoTeclado, jogo *Jogo, playerChannel
 func ConsumirItemEstrela(jogo *Jogo) bool {
 	if jogo.UltimoVisitado.simbolo == StarElementVisible.simbolo {
 		jogo.UltimoVisitado = Vazio
+		jogoAgendarReaparecimento(jogo, shared.ItemStar, StarElementVisible, jogo.PosX, jogo.PosY)
 		return true
 	}
 	return false
@@ -0,0 +1,96 @@
+// predicao.go - predição local do movimento e reconciliação com o servidor
+package main
+
+// MovimentoPendente é um movimento já aplicado localmente que o servidor
+// ainda não confirmou.
+type MovimentoPendente struct {
+	Seq  uint64 // sequência do jogo (enviada em "MOVE x y seq")
+	X, Y int    // posição absoluta enviada
+}
+
+// Confirmação do servidor repassada pelo client (ACK, NACK ou SELFPOS)
+type ConfirmacaoServidor struct {
+	Seq      uint64 // sequência do jogo confirmada
+	X, Y     int    // posição autoritativa após Seq (quando Aplicado)
+	Aplicado bool
+	Motivo   string // motivo da recusa (NACK)
+}
+
+const EventConfirmacaoServidor = "ServerConfirmation"
+
+// Limite de movimentos sem confirmação (ex.: sem client conectado). Acima
+// disso os mais antigos são esquecidos: a predição só depende do último.
+const pendentesMax = 2 * sessaoFilaMax
+
+// jogoRegistrarMovimento guarda o movimento previsto e devolve a sequência a enviar.
+func jogoRegistrarMovimento(jogo *Jogo, dx, dy int) uint64 {
+	if len(jogo.Pendentes) == 0 {
+		// base = última posição que o servidor conhece (antes deste movimento)
+		jogo.BaseX, jogo.BaseY = jogo.PosX-dx, jogo.PosY-dy
+	}
+	jogo.ProximaSeq++
+	jogo.Pendentes = append(jogo.Pendentes, MovimentoPendente{Seq: jogo.ProximaSeq, X: jogo.PosX, Y: jogo.PosY})
+	if n := len(jogo.Pendentes); n > pendentesMax {
+		jogo.Pendentes = append(jogo.Pendentes[:0], jogo.Pendentes[n-pendentesMax:]...)
+	}
+	return jogo.ProximaSeq
+}
+
+// jogoDescartarPendente esquece um movimento que não chegará ao servidor
+// (substituído na fila da sessão).
+func jogoDescartarPendente(jogo *Jogo, seq uint64) {
+	for i, m := range jogo.Pendentes {
+		if m.Seq == seq {
+			jogo.Pendentes = append(jogo.Pendentes[:i], jogo.Pendentes[i+1:]...)
+			return
+		}
+	}
+}
+
+// jogoReconciliar aplica uma confirmação: descarta os movimentos até Seq e
+// ajusta a predição. Os movimentos levam a posição absoluta, então cada um já
+// inclui os anteriores (até um recusado): com pendentes, a predição é a
+// posição do último deles; sem pendentes, a última posição autoritativa.
+func jogoReconciliar(jogo *Jogo, c ConfirmacaoServidor) {
+	if c.Seq == 0 || c.Seq > jogo.ProximaSeq {
+		// confirmação de outra sessão do jogo (ex.: jogo reiniciado)
+		return
+	}
+	restantes := jogo.Pendentes[:0]
+	for _, m := range jogo.Pendentes {
+		if m.Seq > c.Seq {
+			restantes = append(restantes, m)
+		}
+	}
+	jogo.Pendentes = restantes
+
+	if c.Aplicado {
+		jogo.BaseX, jogo.BaseY = c.X, c.Y
+	} else {
+		jogo.StatusMsg = "Movimento recusado pelo servidor: " + c.Motivo
+	}
+
+	// um NACK com movimentos depois dele não volta o jogador: o próximo ACK
+	// (ou Confirmed) traz a posição autoritativa
+	x, y := jogo.BaseX, jogo.BaseY
+	if n := len(jogo.Pendentes); n > 0 {
+		x, y = jogo.Pendentes[n-1].X, jogo.Pendentes[n-1].Y
+	}
+	if x != jogo.PosX || y != jogo.PosY {
+		if !jogoPodeMoverPara(jogo, x, y) {
+			return // base fora do mapa local (mapas diferentes): mantém a predição
+		}
+		jogoReposicionar(jogo, x, y)
+		if c.Aplicado {
+			jogo.StatusMsg = "Posição corrigida pelo servidor"
+		}
+	}
+}
+
+// jogoReposicionar move o personagem sem coletar itens nem notificar o client.
+func jogoReposicionar(jogo *Jogo, x, y int) {
+	jogo.Mapa[jogo.PosY][jogo.PosX] = jogo.UltimoVisitado
+	jogo.UltimoVisitado = jogo.Mapa[y][x]
+	jogo.Mapa[y][x] = Vazio
+	jogo.PosX, jogo.PosY = x, y
+}
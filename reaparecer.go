@@ -0,0 +1,38 @@
+// reaparecer.go - itens coletados voltam ao mapa após o tempo do servidor
+package main
+
+import "time"
+
+// ItemReaparecendo é um item coletado esperando para voltar à sua célula.
+type ItemReaparecendo struct {
+	X, Y     int
+	Elemento Elemento
+	Quando   time.Time
+}
+
+// jogoAgendarReaparecimento marca o item coletado em (x, y) para voltar após
+// o tempo configurado no servidor para ele (item_respawn); sem tempo, o item
+// some de vez.
+func jogoAgendarReaparecimento(jogo *Jogo, item string, e Elemento, x, y int) {
+	d := jogo.TempoReaparecer[item]
+	if d <= 0 {
+		return
+	}
+	jogo.Reaparecimentos = append(jogo.Reaparecimentos, ItemReaparecendo{X: x, Y: y, Elemento: e, Quando: time.Now().Add(d)})
+}
+
+// jogoReaparecerItens devolve ao mapa os itens cujo tempo já passou. Célula
+// ocupada adia o item até ficar livre; o jogador não fica em Mapa, então sua
+// posição é conferida à parte (o item seria arrastado no próximo movimento).
+func jogoReaparecerItens(jogo *Jogo, agora time.Time) {
+	restantes := jogo.Reaparecimentos[:0]
+	for _, r := range jogo.Reaparecimentos {
+		sobJogador := r.X == jogo.PosX && r.Y == jogo.PosY
+		if agora.Before(r.Quando) || sobJogador || jogo.Mapa[r.Y][r.X] != Vazio {
+			restantes = append(restantes, r)
+			continue
+		}
+		jogo.Mapa[r.Y][r.X] = r.Elemento
+	}
+	jogo.Reaparecimentos = restantes
+}
@@ -0,0 +1,272 @@
+// sessao.go - sessão persistente com o cmd/client (movimentos e estado na mesma conexão)
+package main
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"jogo/common/client"
+	"jogo/common/protocol"
+	"jogo/common/shared"
+)
+
+// Movimentos aguardando envio. Acima disso o último é substituído pelo mais
+// novo: as posições são absolutas e a reconciliação descarta a sequência pulada.
+const sessaoFilaMax = 64
+
+// sessaoCliente guarda os movimentos em ordem até o escritor da conexão
+// atual enviá-los; sem conexão eles esperam pela próxima.
+type sessaoCliente struct {
+	mu    sync.Mutex
+	fila  []protocol.Move
+	aviso chan struct{}
+}
+
+func novaSessaoCliente() *sessaoCliente {
+	return &sessaoCliente{aviso: make(chan struct{}, 1)}
+}
+
+// enviarMovimento enfileira um movimento sem bloquear o loop do jogo. Com a
+// fila cheia devolve a sequência do movimento substituído (nunca enviado).
+func (s *sessaoCliente) enviarMovimento(m protocol.Move) (substituido uint64) {
+	s.mu.Lock()
+	if n := len(s.fila); n >= sessaoFilaMax {
+		substituido = s.fila[n-1].Seq
+		s.fila[n-1] = m
+	} else {
+		s.fila = append(s.fila, m)
+	}
+	s.mu.Unlock()
+	select {
+	case s.aviso <- struct{}{}:
+	default:
+	}
+	return substituido
+}
+
+// pendentes informa quantos movimentos ainda não foram escritos.
+func (s *sessaoCliente) pendentes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.fila)
+}
+
+// escrever entrega a fila a enviar até fim ser fechado ou o envio falhar.
+// Um envio lento segura a fila (que coalesce) em vez de bloquear o jogo.
+func (s *sessaoCliente) escrever(enviar func([]protocol.Message) error, fim <-chan struct{}) {
+	for {
+		s.mu.Lock()
+		lote := s.fila
+		s.fila = nil
+		s.mu.Unlock()
+		if len(lote) > 0 {
+			msgs := make([]protocol.Message, len(lote))
+			for i := range lote {
+				msgs[i] = &lote[i]
+			}
+			if err := enviar(msgs); err != nil {
+				// devolve o lote para a próxima conexão, antes dos mais novos
+				s.mu.Lock()
+				s.fila = append(lote, s.fila...)
+				if len(s.fila) > sessaoFilaMax {
+					s.fila = s.fila[len(s.fila)-sessaoFilaMax:]
+				}
+				s.mu.Unlock()
+				return
+			}
+			continue
+		}
+		select {
+		case <-s.aviso:
+		case <-fim:
+			return
+		}
+	}
+}
+
+// Eventos da sessão para o loop do jogo (Data entre parênteses)
+const (
+	EventEstadoServidor = "ServerState"   // *protocol.State
+	EventStatusServidor = "ServerStatus"  // string, "" = online
+	EventMensagemStatus = "StatusMessage" // string para a barra de status
+)
+
+// enviarAoJogo entrega um evento da sessão ao loop do jogo, único dono de
+// Jogo: as goroutines de rede nunca alteram o estado diretamente. Bloqueia
+// em vez de descartar para manter a ordem (o loop drena a cada tick).
+func enviarAoJogo(j *Jogo, tipo string, dados interface{}) {
+	j.ServidorEventos <- GameEvent{Type: tipo, Data: dados}
+}
+
+// Conecta ao client local (127.0.0.1:4001 ou unix:/caminho): recebe mapa/jogadores/confirmações
+// e envia os movimentos pela mesma conexão, reconectando quando ela cai.
+func startStateSync(j *Jogo, addr string) {
+	for {
+		conn, err := protocol.Dial(addr, 2*time.Second)
+		if err != nil {
+			time.Sleep(500 * time.Millisecond)
+			continue
+		}
+		// handshake: anuncia a versão do jogo; o client responde com a dele
+		enc := protocol.NewEncoder(conn)
+		conn.SetWriteDeadline(time.Now().Add(time.Second))
+		if err := enc.Encode(protocol.NewHello()); err != nil {
+			conn.Close()
+			time.Sleep(500 * time.Millisecond)
+			continue
+		}
+		fim := make(chan struct{})
+		go j.Sessao.escrever(func(msgs []protocol.Message) error {
+			conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
+			if err := enc.Encode(msgs...); err != nil {
+				conn.Close()
+				return err
+			}
+			return nil
+		}, fim)
+
+		incompativel := false
+		dec := protocol.NewDecoder(conn)
+		for !incompativel {
+			msg, err := dec.Decode()
+			if err != nil {
+				if errors.Is(err, protocol.ErrMalformed) || errors.Is(err, shared.ErrVersionMismatch) {
+					// client com o protocolo de texto antigo (v1/v2) ou linha corrompida
+					enviarAoJogo(j, EventMensagemStatus, "Cliente local incompatível: "+err.Error())
+					incompativel = true
+				}
+				break
+			}
+			incompativel = tratarMensagem(j, msg)
+		}
+		close(fim)
+		conn.Close()
+		if incompativel {
+			// não adianta insistir rápido com uma versão incompatível
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		// reconectar em caso de fechamento
+		time.Sleep(300 * time.Millisecond)
+	}
+}
+
+// startSessaoEmbutida liga o jogo ao client no mesmo processo (--server):
+// as mensagens vão por canais, sem TCP. Reabre a sessão se ela terminar.
+func startSessaoEmbutida(j *Jogo, c *client.Client) {
+	for {
+		sess := c.OpenInProcessSession()
+		fim := make(chan struct{})
+		go j.Sessao.escrever(func(msgs []protocol.Message) error {
+			for _, m := range msgs {
+				if err := sess.Send(m); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, fim)
+	leitura:
+		for {
+			select {
+			case msg := <-sess.Messages():
+				tratarMensagem(j, msg)
+			case <-sess.Done():
+				break leitura
+			}
+		}
+		close(fim)
+		time.Sleep(300 * time.Millisecond)
+	}
+}
+
+// tratarMensagem repassa uma mensagem do client ao loop do jogo. Retorna
+// true se o client recusou a sessão ou fala uma versão incompatível.
+func tratarMensagem(j *Jogo, msg protocol.Message) (incompativel bool) {
+	switch m := msg.(type) {
+	case *protocol.Hello:
+		if err := shared.CheckLocalVersion(m.Version); err != nil {
+			enviarAoJogo(j, EventMensagemStatus, "Cliente local incompatível: "+err.Error())
+			return true
+		}
+	case *protocol.Error:
+		enviarAoJogo(j, EventMensagemStatus, "Cliente local recusou a conexão: "+m.Message)
+		return true
+	case *protocol.State:
+		enviarAoJogo(j, EventEstadoServidor, m)
+	case *protocol.Status:
+		// online | reconnecting…
+		if m.Text == "online" {
+			enviarAoJogo(j, EventStatusServidor, "")
+		} else {
+			enviarAoJogo(j, EventStatusServidor, m.Text)
+		}
+	case *protocol.Ack:
+		enviarAoJogo(j, EventConfirmacaoServidor, confirmacaoDe(m))
+	case *protocol.Chat:
+		if m.From != "" {
+			enviarAoJogo(j, EventMensagemStatus, m.From+": "+m.Text)
+		} else {
+			enviarAoJogo(j, EventMensagemStatus, m.Text)
+		}
+	}
+	return false
+}
+
+// aplicarEstado aplica um snapshot do client (no loop do jogo): mapa (ao
+// conectar e quando o servidor troca de mapa), jogadores remotos, fila,
+// latência e confirmação.
+func aplicarEstado(j *Jogo, st *protocol.State) {
+	if st.Self != "" {
+		j.SelfID = st.Self
+	}
+	chave := strings.Join(st.Map, "\n")
+	if len(st.Map) > 0 && chave != j.MapaServidor {
+		primeiro := j.MapaServidor == ""
+		_ = jogoCarregarMapaDeLinhas(st.Map, j)
+		j.MapaServidor = chave
+		jogoIniciarMonstros(j)
+		// mapa novo: se a posição atual ficou inválida, volta ao início do mapa
+		if !primeiro && !jogoPodeMoverPara(j, j.PosX, j.PosY) {
+			if x, y, ok := jogoPosicaoInicial(st.Map); ok {
+				j.PosX, j.PosY = x, y
+				j.UltimoVisitado = Vazio
+			}
+		}
+	}
+	j.FilaComandos = st.Queue
+	j.TempoReaparecer = st.ItemRespawn
+	if st.Ping != nil {
+		j.Ping = st.Ping.RTT
+		j.ClockOffset = st.Ping.Offset
+		j.PingConhecido = true
+	}
+	// horário do snapshot no relógio local (recebimento, se o servidor não
+	// informa ou o relógio ainda não foi estimado)
+	t := time.Now()
+	if !st.Time.IsZero() && j.PingConhecido {
+		t = st.Time.Add(-j.ClockOffset)
+	}
+	// repovoa os players, continuando o histórico de quem já estava na sala
+	remotos := make(map[string]RemotePlayer, len(st.Players))
+	for _, p := range st.Players {
+		rp := RemotePlayer{ID: p.ID, Name: p.Name, X: p.X, Y: p.Y, Visto: t}
+		if !p.Seen.IsZero() && !st.Time.IsZero() {
+			rp.Visto = t.Add(-st.Time.Sub(p.Seen)) // idade medida no relógio do servidor
+		}
+		if ant, ok := j.RemotePlayers[p.ID]; ok {
+			rp.Amostras = ant.Amostras
+		}
+		remotos[p.ID] = jogoNovaAmostra(j, rp, t)
+	}
+	j.RemotePlayers = remotos
+	if st.Confirmed != nil {
+		jogoReconciliar(j, confirmacaoDe(st.Confirmed))
+	}
+}
+
+// confirmacaoDe converte um Ack do client para a reconciliação da predição.
+func confirmacaoDe(a *protocol.Ack) ConfirmacaoServidor {
+	return ConfirmacaoServidor{Seq: a.Seq, X: a.X, Y: a.Y, Aplicado: a.Applied, Motivo: a.Reason}
+}
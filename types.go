@@ -1,6 +1,8 @@
 // types.go - Definições de tipos para elementos especiais
 package main
 
+import "time"
+
 type Position struct {
 	X, Y int
 }
@@ -15,12 +17,25 @@ const (
 
 // Structs dos elementos especiais
 type Monster struct {
-	current_position Position     // Posição atual do monster
-	shift_count      int          // Contador para movimento a cada 2 turnos
-	destiny_position Position     // Posição de destino (patrulha)
-	last_seen        Position     // Última posição vista do jogador
-	state            MonsterState // Estado atual (hunting/patrolling)
-	id               string       // ID único do monster
+	current_position Position         // Posição atual do monster
+	shift_count      int              // Contador para movimento a cada 2 turnos
+	destiny_position Position         // Posição de destino (patrulha)
+	last_seen        Position         // Última posição vista do jogador
+	state            MonsterState     // Estado atual (hunting/patrolling)
+	seeing_player    bool             // jogador na linha de visada na última posição recebida
+	id               string           // ID único do monster
+	moves            chan Position    // posições aceitas pelo loop do jogo
+	player           chan PlayerState // cópia da posição do jogador para este monstro
+	alerts           chan PlayerAlert // cópia dos alertas do jogador
+	mapa             mapaLeitura      // células livres (somente leitura)
+	caminho          []Position       // passos até caminho_alvo (cache)
+	caminho_alvo     Position
+	area             map[Position]bool // células alcançáveis a partir do spawn
+	waypoints        []Position        // pontos de patrulha do mapa dentro da área
+	visao            Visao             // alcance e cone de visão
+	facing           Position          // direção do último passo
+	drawn_facing     Position          // idem para drawn_position; só o loop do jogo acessa
+	drawn_position   Position          // posição aceita; só o loop do jogo acessa
 }
 
 type StarBonus struct {
@@ -48,15 +63,18 @@ type PlayerAlert struct {
 }
 
 type PlayerState struct {
-	X, Y int
+	X, Y      int
+	Invisivel bool // monstros ignoram a posição de um jogador invisível
 }
 
 // Representa um jogador remoto renderizado no mapa
 type RemotePlayer struct {
-	ID   string
-	Name string
-	X    int
-	Y    int
+	ID       string
+	Name     string
+	X        int
+	Y        int
+	Amostras []AmostraRemota // últimos snapshots, para interpolar o movimento
+	Visto    time.Time       // último contato do jogador com o servidor, no relógio local
 }
 
 type PlayerCollect struct {
@@ -0,0 +1,129 @@
+// visao.go - campo de visão dos monstros (linha de visada, cone e vegetação)
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Visao é o campo de visão de um monstro.
+type Visao struct {
+	Alcance float64 // em células
+	Cone    float64 // abertura em graus, centrada na direção do último passo; 360 = todas
+}
+
+var visaoPadrao = Visao{Alcance: 25, Cone: 360}
+
+const (
+	// cada célula de vegetação entre o monstro e o alvo gasta esse tanto de alcance
+	custoVegetacao = 3
+	// jogador dentro da vegetação só é visto de perto
+	alcanceNaVegetacao = 2
+)
+
+// parseVisoes lê a flag --monster-vision: "alcance/cone" por monstro, na
+// ordem do mapa, separados por vírgula ("25/360,12/90"). O cone é opcional
+// (360) e a última entrada vale para os monstros restantes.
+func parseVisoes(s string) ([]Visao, error) {
+	var vs []Visao
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		v := Visao{Cone: 360}
+		alcance, cone, temCone := strings.Cut(item, "/")
+		var err error
+		if v.Alcance, err = strconv.ParseFloat(alcance, 64); err != nil || v.Alcance < 0 {
+			return nil, fmt.Errorf("invalid monster vision %q: range must be a non-negative number", item)
+		}
+		if temCone {
+			if v.Cone, err = strconv.ParseFloat(cone, 64); err != nil || v.Cone <= 0 || v.Cone > 360 {
+				return nil, fmt.Errorf("invalid monster vision %q: cone must be in (0, 360] degrees", item)
+			}
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+// visaoDoMonstro escolhe a visão do i-ésimo monstro do mapa.
+func visaoDoMonstro(vs []Visao, i int) Visao {
+	switch {
+	case len(vs) == 0:
+		return visaoPadrao
+	case i >= len(vs):
+		return vs[len(vs)-1]
+	}
+	return vs[i]
+}
+
+// enxerga informa se um monstro em de, virado para frente, vê a célula alvo:
+// dentro do alcance e do cone, sem parede no caminho do olhar, com a vegetação
+// encurtando o alcance e escondendo quem está dentro dela.
+func (v Visao) enxerga(m mapaLeitura, de, frente, alvo Position) bool {
+	if alvo == de {
+		return true
+	}
+	dx, dy := float64(alvo.X-de.X), float64(alvo.Y-de.Y)
+	d := math.Hypot(dx, dy)
+	if d > v.Alcance {
+		return false
+	}
+	if m.vegetacao(alvo.X, alvo.Y) && d > alcanceNaVegetacao {
+		return false
+	}
+	if v.Cone < 360 && frente != (Position{}) {
+		fx, fy := float64(frente.X), float64(frente.Y)
+		cos := (fx*dx + fy*dy) / (math.Hypot(fx, fy) * d)
+		if math.Acos(math.Max(-1, math.Min(1, cos)))*180/math.Pi > v.Cone/2 {
+			return false
+		}
+	}
+	custo, livre := d, true
+	linhaDeVisada(de, alvo, func(x, y int) bool {
+		if m.opaca(x, y) {
+			livre = false
+			return false
+		}
+		if m.vegetacao(x, y) {
+			custo += custoVegetacao - 1
+		}
+		return true
+	})
+	return livre && custo <= v.Alcance
+}
+
+// linhaDeVisada percorre as células entre a e b (exclusive) pela reta de
+// Bresenham, até visitar devolver false.
+func linhaDeVisada(a, b Position, visitar func(x, y int) bool) {
+	dx, dy := abs(b.X-a.X), -abs(b.Y-a.Y)
+	sx, sy := 1, 1
+	if a.X > b.X {
+		sx = -1
+	}
+	if a.Y > b.Y {
+		sy = -1
+	}
+	erro := dx + dy
+	x, y := a.X, a.Y
+	for {
+		e2 := 2 * erro
+		if e2 >= dy {
+			erro += dy
+			x += sx
+		}
+		if e2 <= dx {
+			erro += dx
+			y += sy
+		}
+		if x == b.X && y == b.Y {
+			return
+		}
+		if !visitar(x, y) {
+			return
+		}
+	}
+}